@@ -0,0 +1,182 @@
+package com
+
+import (
+	"bytes"
+	"strings"
+)
+
+var theS2TMap = make(map[rune]rune)
+var theT2SMap = make(map[rune]rune)
+var theS2TPhraseMap = make(map[string]string) // key is Simplified phrase
+var theMaxS2TPhraseLen int                    // in characters
+var thePinyinMap = make(map[rune]string)
+
+func init() {
+	for _, pair := range strings.Fields(theS2TChars) {
+		chars := []rune(pair)
+		if _, ok := theS2TMap[chars[0]]; !ok {
+			theS2TMap[chars[0]] = chars[1]
+		}
+		if _, ok := theT2SMap[chars[1]]; !ok {
+			theT2SMap[chars[1]] = chars[0]
+		}
+	}
+
+	for _, pair := range strings.Fields(theT2SOnlyChars) {
+		chars := []rune(pair)
+		theT2SMap[chars[0]] = chars[1]
+	}
+
+	for _, pair := range strings.Fields(theS2TPhrases) {
+		chars := []rune(pair)
+		n := len(chars) / 2
+		theS2TPhraseMap[string(chars[:n])] = string(chars[n:])
+		if n > theMaxS2TPhraseLen {
+			theMaxS2TPhraseLen = n
+		}
+	}
+
+	for _, line := range theCharPinyins {
+		fields := strings.Fields(line)
+		for _, c := range fields[1] {
+			thePinyinMap[c] = fields[0]
+		}
+	}
+}
+
+// ToSimplified is converting Traditional Chinese characters to Simplified, others are not changed.
+func ToSimplified(s string) string {
+	return strings.Map(func(c rune) rune {
+		if sc, ok := theT2SMap[c]; ok {
+			return sc
+		}
+		return c
+	}, s)
+}
+
+// ToTraditional is converting Simplified Chinese to Traditional by phrases and characters, others are not changed.
+func ToTraditional(s string) string {
+	text := []rune(s)
+
+	var buf bytes.Buffer
+	for i := 0; i < len(text); {
+		// the longest phrase first
+		matched := false
+		for n := theMaxS2TPhraseLen; n > 1 && !matched; n-- {
+			if i+n > len(text) {
+				continue
+			}
+
+			if phrase, ok := theS2TPhraseMap[string(text[i:i+n])]; ok {
+				buf.WriteString(phrase)
+				i += n
+				matched = true
+			}
+		}
+		if matched {
+			continue
+		}
+
+		c := text[i]
+		if tc, ok := theS2TMap[c]; ok {
+			c = tc
+		}
+		buf.WriteRune(c)
+		i++
+	}
+
+	return buf.String()
+}
+
+// NormalizeChinese is lower-case Simplified string for comparison.
+func NormalizeChinese(s string) string {
+	return ToSimplified(strings.ToLower(s))
+}
+
+// GetPinyin is getting pinyin without tones and its initials, e.g. 琅琊榜 is langyabang and lyb.
+// Characters without pinyin are kept in lower case, and separators are removed.
+func GetPinyin(s string) (string, string) {
+	var full, initials bytes.Buffer
+	for _, key := range Split2Keywords(ToSimplified(s)) {
+		for _, c := range key {
+			if pinyin, ok := thePinyinMap[c]; ok {
+				full.WriteString(pinyin)
+				initials.WriteByte(pinyin[0])
+			} else {
+				full.WriteRune(c)
+				initials.WriteRune(c)
+			}
+		}
+	}
+
+	return full.String(), initials.String()
+}
+
+const (
+	minPinyinKeySize   = 2
+	minPinyinSyllables = 2 // in middle of name, or single syllable like de matches too many names
+)
+
+// isPinyinKey is true if @key might be pinyin typed by user, only letters.
+func isPinyinKey(key string) bool {
+	if len(key) < minPinyinKeySize {
+		return false
+	}
+
+	for _, c := range key {
+		if c < 'a' || c > 'z' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// getPinyinSyllables is getting pinyin of each character in @s, and whether it is the first of word.
+// Characters without pinyin are kept in lower case as syllables.
+func getPinyinSyllables(s string) ([]string, []bool) {
+	var syllables []string
+	var bStarts []bool
+	for _, key := range Split2Keywords(NormalizeChinese(s)) {
+		for i, c := range []rune(key) {
+			pinyin, ok := thePinyinMap[c]
+			if !ok {
+				pinyin = string(c)
+			}
+			syllables = append(syllables, pinyin)
+			bStarts = append(bStarts, i == 0)
+		}
+	}
+
+	return syllables, bStarts
+}
+
+// MatchPinyin is true if @key is pinyin of whole characters of Chinese @s, or prefix of its initials,
+// e.g. langya, yabang or lyb for 琅琊榜, but not ban or ya.
+// Pinyin of one character only matches at the beginning of words.
+// @key: lower case
+func MatchPinyin(s string, key string) bool {
+	if !isPinyinKey(key) || !HasChinese(s) {
+		return false
+	}
+
+	_, initials := GetPinyin(s)
+	if strings.HasPrefix(initials, key) {
+		return true
+	}
+
+	syllables, bStarts := getPinyinSyllables(s)
+	for i := range syllables {
+		rest, n := key, 0
+		for j := i; j < len(syllables) && rest != "" && strings.HasPrefix(rest, syllables[j]); j++ {
+			rest = rest[len(syllables[j]):]
+			n++
+		}
+
+		if rest == "" && (bStarts[i] || n >= minPinyinSyllables) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,103 @@
+package com
+
+import "testing"
+
+func TestToSimplified(t *testing.T) {
+	tests := []struct {
+		s, want string
+	}{
+		{"權力的遊戲", "权力的游戏"},
+		{"瑯琊榜", "琅琊榜"},
+		{"陰屍路", "阴尸路"},
+		{"後會無期", "后会无期"},
+		{"頭髮亂了", "头发乱了"},
+		{"Game of Thrones 權力", "Game of Thrones 权力"},
+	}
+
+	for _, test := range tests {
+		if got := ToSimplified(test.s); got != test.want {
+			t.Errorf("ToSimplified(%s) = %s, want %s", test.s, got, test.want)
+		}
+	}
+}
+
+func TestToTraditional(t *testing.T) {
+	tests := []struct {
+		s, want string
+	}{
+		// phrases for ambiguous characters
+		{"权力的游戏", "權力的遊戲"},
+		{"后会无期", "後會無期"},
+		{"头发", "頭髮"},
+		{"干杯", "乾杯"},
+		{"复仇者联盟", "復仇者聯盟"},
+		{"这里", "這裡"},
+
+		// characters only
+		{"琅琊榜", "琅琊榜"},
+		{"龙门飞甲", "龍門飛甲"},
+		{"Game of Thrones", "Game of Thrones"},
+	}
+
+	for _, test := range tests {
+		if got := ToTraditional(test.s); got != test.want {
+			t.Errorf("ToTraditional(%s) = %s, want %s", test.s, got, test.want)
+		}
+	}
+}
+
+func TestGetPinyin(t *testing.T) {
+	tests := []struct {
+		s, full, initials string
+	}{
+		{"琅琊榜", "langyabang", "lyb"},
+		{"權力的遊戲", "quanlideyouxi", "qldyx"},
+		{"捉妖记2", "zhuoyaoji2", "zyj2"},
+		{"行尸走肉 / 陰屍路", "xingshizourouyinshilu", "xszrysl"},
+	}
+
+	for _, test := range tests {
+		full, initials := GetPinyin(test.s)
+		if full != test.full || initials != test.initials {
+			t.Errorf("GetPinyin(%s) = %s, %s, want %s, %s", test.s, full, initials, test.full, test.initials)
+		}
+	}
+}
+
+func TestMatchPinyin(t *testing.T) {
+	tests := []struct {
+		s, key string
+		want   bool
+	}{
+		{"琅琊榜", "langyabang", true},
+		{"琅琊榜", "langya", true},
+		{"琅琊榜", "yabang", true},
+		{"琅琊榜", "lang", true},
+		{"琅琊榜", "lyb", true},
+		{"琅琊榜", "ly", true},
+		{"權力的遊戲", "youxi", true},
+		{"權力的遊戲", "quanli", true},
+		{"行尸走肉 / 陰屍路", "yinshilu", true},
+		{"行尸走肉 / 陰屍路", "yin", true},
+
+		// not whole syllables
+		{"琅琊榜", "ban", false},
+		{"琅琊榜", "angya", false},
+		{"琅琊榜", "langy", false},
+
+		// one syllable in middle
+		{"琅琊榜", "ya", false},
+		{"權力的遊戲", "de", false},
+
+		// not pinyin
+		{"權力的遊戲", "game", false},
+		{"琅琊榜", "l", false},
+		{"Game of Thrones", "game", false},
+	}
+
+	for _, test := range tests {
+		if got := MatchPinyin(test.s, test.key); got != test.want {
+			t.Errorf("MatchPinyin(%s, %s) = %v, want %v", test.s, test.key, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,492 @@
+package com
+
+// Tables of Simplified/Traditional Chinese and pinyin, for common characters in movie and TV names.
+
+// Simplified and Traditional pairs converted in both directions, each is 2 characters.
+var theS2TChars = `
+这這 个個 们們 来來 时時 为為 说說 国國 过過 会會 对對 发發 学學 么麼 经經 还還 进進 种種 样樣 动動
+现現 实實 开開 长長 关關 点點 机機 与與 问問 体體 当當 从從 万萬 无無 头頭 见見 两兩 东東 门門 马馬
+车車 爱愛 战戰 龙龍 风風 云雲 飞飛 华華 电電 视視 剧劇 传傳 记記 梦夢 乐樂 欢歡 乡鄉 亲親 恋戀 将將
+军軍 帅帥 师師 术術 声聲 灵靈 杀殺 侠俠 剑劍 枪槍 罗羅 兰蘭 红紅 绿綠 蓝藍 黄黃 银銀 铁鐵 钢鋼 镜鏡
+间間 闻聞 阳陽 阴陰 队隊 际際 陆陸 险險 难難 鸡雞 鸟鳥 鱼魚 鲁魯 鲜鮮 贝貝 负負 贵貴 买買 卖賣 费費
+资資 财財 货貨 贼賊 赵趙 赛賽 变變 亚亞 众眾 优優 伤傷 价價 伦倫 侦偵 劳勞 势勢 区區 医醫 单單 卫衛
+历歷 厅廳 县縣 双雙 叶葉 号號 听聽 启啟 员員 咏詠 团團 园園 围圍 图圖 圣聖 场場 坏壞 块塊 坚堅 坛壇
+垒壘 夺奪 奋奮 妇婦 妈媽 娱娛 孙孫 宝寶 宁寧 宪憲 审審 寻尋 导導 层層 岁歲 岛島 岭嶺 币幣 带帶 帮幫
+广廣 庄莊 庆慶 库庫 应應 废廢 异異 弹彈 强強 归歸 录錄 彦彥 彻徹 忆憶 怀懷 态態 恶惡 恼惱 悦悅 惊驚
+惧懼 惯慣 愤憤 忧憂 戏戲 户戶 扩擴 扫掃 护護 报報 担擔 拥擁 择擇 挂掛 挥揮 损損 换換 据據 摄攝 击擊
+敌敵 数數 断斷 旧舊 显顯 晓曉 暂暫 杂雜 权權 条條 杨楊 极極 构構 标標 树樹 桥橋 检檢 楼樓 欧歐 残殘
+毕畢 气氣 汉漢 汤湯 沟溝 没沒 沧滄 泪淚 泽澤 洁潔 浅淺 测測 济濟 浓濃 涛濤 涨漲 润潤 渐漸 温溫 湾灣
+湿濕 满滿 滚滾 灭滅 灯燈 灾災 炉爐 炼煉 烟煙 热熱 爷爺 牵牽 犹猶 狮獅 独獨 狱獄 猎獵 猫貓 献獻 环環
+玛瑪 画畫 畅暢 疗療 疯瘋 盖蓋 监監 盘盤 睁睜 码碼 砖磚 础礎 礼禮 祸禍 离離 积積 称稱 穷窮 窃竊 竞競
+笔筆 笼籠 筑築 简簡 类類 粮糧 紧緊 纪紀 约約 级級 纯純 纵縱 线線 练練 组組 细細 终終 结結 绝絕 给給
+统統 继繼 续續 绵綿 编編 缘緣 网網 罚罰 职職 联聯 肃肅 脑腦 脸臉 腊臘 舰艦 艺藝 节節 苏蘇 苹蘋 荣榮
+药藥 莲蓮 获獲 萝蘿 营營 蛮蠻 补補 装裝 观觀 规規 览覽 觉覺 誉譽 计計 订訂 认認 讨討 让讓 训訓 议議
+讯訊 讲講 许許 论論 设設 访訪 证證 评評 识識 诉訴 词詞 译譯 试試 诗詩 诚誠 话話 诞誕 询詢 该該 详詳
+语語 误誤 请請 诸諸 读讀 谁誰 调調 谈談 谋謀 谍諜 谎謊 谜謎 谢謝 谣謠 贞貞 败敗 贤賢 质質 购購 贺賀
+赌賭 赏賞 赢贏 赶趕 跃躍 践踐 踪蹤 轨軌 转轉 轮輪 软軟 轻輕 载載 较較 辉輝 辑輯 输輸 辞辭 边邊 达達
+迁遷 运運 远遠 违違 连連 迟遲 适適 选選 递遞 逻邏 遗遺 邮郵 邻鄰 郑鄭 酱醬 释釋 针針 钓釣 钱錢 铃鈴
+链鏈 销銷 锁鎖 锋鋒 锦錦 键鍵 镇鎮 闪閃 闭閉 闯闖 闲閒 阁閣 阅閱 阔闊 阵陣 陈陳 随隨 隐隱 雾霧 静靜
+韩韓 页頁 顶頂 项項 顺順 须須 顾顧 顿頓 预預 领領 频頻 题題 颜顏 额額 飘飄 饭飯 饮飲 饰飾 饱飽 饿餓
+馆館 驱驅 驶駛 驾駕 骑騎 验驗 骗騙 鲸鯨 鸣鳴 鸭鴨 鹰鷹 麦麥 齐齊 龟龜 侣侶 俩倆 债債 倾傾 储儲 兴興
+养養 决決 冻凍 净淨 凤鳳 凭憑 创創 刘劉 则則 刚剛 删刪 别別 办辦 务務 勋勛 胜勝 压壓 厉厲 参參 叹嘆
+吗嗎 吴吳 呜嗚 响響 哑啞 唤喚 喷噴 嘘噓 坟墳 垫墊 墙牆 壮壯 处處 备備 夹夾 夸誇 奖獎 妆妝 娇嬌 婴嬰
+宽寬 宾賓 寿壽 尔爾 尘塵 尝嘗 尽盡 届屆 屿嶼 岗崗 峡峽 帐帳 并並 庙廟 庞龐 张張 弯彎 径徑 怜憐 总總
+恳懇 悬懸 惨慘 惩懲 愿願 懒懶 执執 扬揚 抢搶 抚撫 拟擬 拨撥 挡擋 挤擠 挣掙 捞撈 捡撿 摆擺 摇搖 撑撐
+敛斂 斩斬 旷曠 昙曇 昼晝 晋晉 晒曬 暧曖 杰傑 枣棗 栋棟 栏欄 桨槳 椭橢 横橫 歼殲 毁毀 汇匯 汹洶 沦淪
+泼潑 洒灑 浊濁 浏瀏 浑渾 涂塗 涌湧 涡渦 渊淵 渔漁 溃潰 滞滯 滤濾 滨濱 潇瀟 潜潛 澜瀾 灿燦 烂爛 烛燭
+烦煩 烧燒 烫燙 焕煥 状狀 狈狽 狞獰 猪豬 珑瓏 琐瑣 瑶瑤 畴疇 痒癢 痴癡 瘾癮 皱皺 盏盞 盐鹽 矫矯 矶磯
+硕碩 确確 碍礙 祷禱 禅禪 秃禿 税稅 窝窩 竖豎 笋筍 筛篩 签簽 篮籃 篱籬 粤粵 纠糾 纤纖 纳納 纷紛 纸紙
+纹紋 纺紡 织織 绑綁 绕繞 绘繪 绣繡 绩績 绪緒 绳繩 维維 综綜 缓緩 缝縫 缠纏 缩縮 罢罷 羡羨 翘翹 耸聳
+聋聾 肠腸 肤膚 肿腫 胁脅 胆膽 脉脈 脚腳 腾騰 舆輿 舱艙 艰艱 芜蕪 苍蒼 茧繭 荐薦 荡蕩 莹瑩 蒋蔣 虏虜
+虑慮 虚虛 虫蟲 虾蝦 蚀蝕 蚁蟻 蜡蠟 衬襯 袭襲 裤褲 觅覓 诀訣 诅詛 诈詐 诊診 诡詭 诱誘 诺諾 谊誼 谐諧
+谦謙 谨謹 谱譜 贡貢 贫貧 贩販 贪貪 贬貶 贯貫 贴貼 贷貸 贸貿 赐賜 赔賠 赖賴 赚賺 赞贊 赠贈 躯軀 辆輛
+辈輩 辩辯 迈邁 迹跡 逊遜 遥遙 邓鄧 酿釀 钉釘 钞鈔 钻鑽 铜銅 铭銘 锅鍋 锐銳 错錯 锡錫 锤錘 闷悶 闸閘
+闹鬧 闺閨 阎閻 阶階 陨隕 隶隸 雏雛 韵韻 颁頒 颂頌 颈頸 颗顆 饼餅 驰馳 驻駐 骂罵 骄驕 骤驟 髅髏 鲍鮑
+鳄鱷 鸽鴿 鹅鵝 鹏鵬 鹤鶴 齿齒 龄齡 宠寵 忏懺 诛誅 丛叢 丝絲 丢丟 严嚴 丧喪 丰豐 临臨 为為 举舉 义義
+乌烏 书書 买買 争爭 亏虧 产產 亿億 仅僅 从從 仑侖 仓倉 仪儀 们們 众眾 优優 伙夥 会會 伞傘 伟偉 传傳
+伪偽 体體 佣傭 侨僑 侧側 侬儂 俭儉 债債 偿償 儿兒 党黨 兰蘭 关關 兴興 兹茲 养養 兽獸 内內 册冊 写寫
+农農 冯馮 况況 冻凍 准準 凉涼 减減 凑湊 凛凜 几幾 凤鳳 凯凱 刍芻 刹剎 剂劑 剥剝 剧劇 劝勸 办辦 动動
+励勵 劲勁 势勢 勋勛 匀勻 华華 协協 单單 卖賣 卢盧 卤滷 卧臥 却卻 厂廠 厌厭 县縣 叁叄 发發 变變 叠疊
+吓嚇 吕呂 员員 呐吶 呕嘔 呛嗆 咙嚨 咸鹹 哗嘩 哟喲 唢嗩 啰囉 啸嘯 喽嘍 嗳噯 团團 国國 图圖 圆圓 圣聖
+场場 坝壩 坠墜 垄壟 垦墾 堕墮 壳殼 壶壺 夺奪 奥奧 妈媽 娄婁 孪孿 孙孫 宁寧 宝寶 实實 宪憲
+宫宮 对對 导導 寿壽 将將 尔爾 尘塵 尧堯 尴尷 层層 屉屜 属屬 岂豈 岖嶇 岚嵐 峦巒 崭嶄 巩鞏
+币幣 师師 帜幟 帧幀 并並 广廣 庆慶 库庫 庐廬 应應 庙廟 开開 异異 弃棄 张張 弥彌 弯彎 归歸 当當
+录錄 彻徹 忆憶 忏懺 怂慫 怅悵 怆愴 态態 怃憮 恸慟 恻惻 恽惲 悯憫 惬愜 惭慚 惮憚 惯慣 愤憤 戆戇
+战戰 戏戲 扑撲 执執 扩擴 扪捫 扫掃 扰擾 抛拋 抠摳 抡掄 抢搶 护護 报報 拢攏 拣揀 拦攔 拧擰 拨撥 择擇
+挚摯 挛攣 挞撻 挟挾 挠撓 挡擋 挥揮 捣搗 掳擄 掴摑 掷擲 掸撣 掺摻 揽攬 搀攙 搁擱 搂摟 搅攪
+携攜 摊攤 撵攆 擞擻 敌敵 敛斂 斋齋 斓斕 无無 旧舊 时時 旷曠 昵暱 晕暈 暂暫 术術 机機 杀殺 杂雜 权權
+杆桿 杠槓 条條 来來 杨楊 极極 枞樅 枢樞 枪槍 枫楓 柜櫃 柠檸 栀梔 栅柵 标標 栈棧 栉櫛 树樹 样樣 档檔
+桠椏 桤榿 梦夢 检檢 棂櫺 椁槨 槛檻 欢歡 欤歟 殇殤 殴毆 毂轂 毕畢 毙斃 气氣 氢氫 汉漢 汤湯 汹洶
+沟溝 沣灃 沪滬 泸瀘 泻瀉 泾涇 洁潔 洼窪 浃浹 浆漿 浇澆 浈湞 浍澮 济濟 浔潯 涝澇 涣渙 涤滌 涧澗 涩澀
+渍漬 渗滲 渐漸 渔漁 渖瀋 湾灣 湿濕 溅濺 滗潷 滦灤 滩灘 漓灕 潆瀠 潋瀲 潍濰 潴瀦 澜瀾 濑瀨 灏灝 灿燦
+炀煬 炜煒 炝熗 烁爍 烬燼 烩燴 烫燙 焖燜 熏燻 爱愛 牍牘 牺犧 犊犢 犷獷 犸獁 狈狽 狲猻 猃獫
+猕獼 猡玀 猬蝟 玑璣 玮瑋 环環 现現 玺璽 珐琺 珲琿 琏璉 琼瓊 瑷璦 璎瓔 瓒瓚 瓯甌 电電 画畫 畅暢 疖癤
+疗療 疮瘡 疯瘋 疱皰 痈癰 痉痙 痨癆 痫癇 瘘瘻 瘪癟 瘫癱 皑皚 皲皸 盗盜 盘盤 眍瞘 眬矓 睑瞼 瞒瞞 矾礬
+矿礦 砀碭 码碼 砚硯 砺礪 砻礱 础礎 硁硜 硖硤 硗磽 确確 碍礙 碛磧 碜磣 祢禰 祯禎 祸禍 禀稟 种種
+积積 秽穢 稣穌 稳穩 穑穡 窍竅 窑窯 窜竄 窥窺 窦竇 竞競 笃篤 笺箋 筚篳 筝箏 筹籌 筼篔 签簽 箦簀 箧篋
+箨籜 箩籮 箫簫 篑簣 篓簍 簖籪 籁籟 粜糶 粝糲 粪糞 粮糧 紧緊 纡紆 纣紂 纥紇 纨紈 纩纊 纫紉 纬緯 纭紜
+纰紕 纱紗 纲綱 纴紝 纶綸 绀紺 绁紲 绂紱 绅紳 绉縐 绊絆 绋紼 绌絀 绍紹 绎繹 经經 绐紿 绒絨 绔絝 绖絰
+绗絎 绚絢 绛絳 络絡 绞絞 绠綆 绡綃 绢絹 绤綌 绥綏 绦縧 继繼 绨綈 绫綾 绮綺 绯緋 绰綽 绲緄 绸綢 绹綯
+绺綹 绻綣 绽綻 绾綰 缀綴 缁緇 缂緙 缃緗 缄緘 缅緬 缆纜 缇緹 缈緲 缉緝 缊縕 缋繢 缌緦 缍綞 缎緞 缏緶
+缑緱 缒縋 缔締 缕縷 缗緡 缙縉 缚縛 缛縟 缜縝 缟縞 缡縭 缢縊 缣縑 缤繽 缥縹 缦縵 缧縲 缨纓 缪繆 缫繅
+缬纈 缭繚 缮繕 缯繒 缰韁 缱繾 缲繰 缳繯 缴繳 罂罌 罗羅 罴羆 羁羈 翚翬 翘翹 耢耮 耧耬 耻恥 聂聶 聍聹
+聪聰 聩聵 肃肅 肤膚 肾腎 肴餚 胀脹 胜勝 胧朧 胪臚 胫脛 胶膠 脍膾 脏臟 脐臍 脑腦 脓膿 脔臠 脶腡 腻膩
+腼靦 腽膃 膑臏 舣艤 舻艫 艳豔 芦蘆 芸蕓 苁蓯 苇葦 苋莧 苌萇 苎苧 茏蘢 茑蔦 茔塋 茕煢 荆荊 荙薘 荚莢
+荛蕘 荜蓽 荞蕎 荟薈 荠薺 荣榮 荤葷 荥滎 荦犖 荧熒 荨蕁 荪蓀 荫蔭 荬蕒 荭葒 莅蒞 莱萊 莴萵 莶薟 莸蕕
+莺鶯 萤螢 萦縈 萧蕭 萨薩 葱蔥 蒇蕆 蒉蕢 蒌蔞 蓟薊 蓠蘺 蓣蕷 蓥鎣 蓦驀 蔷薔 蔹蘞 蔺藺 蔼藹 蕲蘄 蕴蘊
+薮藪 藓蘚 蘖櫱 虏虜 虑慮 虬虯 虮蟣 虽雖 虿蠆 蚀蝕 蚂螞 蚕蠶 蚬蜆 蛊蠱 蛎蠣 蛏蟶 蛰蟄 蛱蛺 蛲蟯 蛳螄
+蛴蠐 蜕蛻 蜗蝸 蝇蠅 蝈蟈 蝉蟬 蝼螻 蝾蠑 螀螿 螨蟎 衔銜 袅裊 袜襪 袯襏 袴褲 裆襠 裢褳 裣襝 裤褲 裥襇
+褛褸 褴襤 见見 观觀 规規 觅覓 视視 觇覘 览覽 觉覺 觊覬 觋覡 觌覿 觎覦 觏覯 觐覲 觑覷 觞觴 触觸 觯觶
+尸屍 乱亂
+`
+
+// Traditional characters only converted to Simplified, their Simplified characters are ambiguous or also used as they are.
+// e.g. 後 and 后 are both 后, but 皇后 is not 皇後.
+var theT2SOnlyChars = `
+後后 遊游 裡里 裏里 乾干 髮发 麵面 鬥斗 範范 鬱郁 瀋沈 捨舍 髒脏 禦御 颱台 臺台 檯台 週周 隻只 祇只
+衝冲 鍾钟 鐘钟 繫系 係系 噁恶 穀谷 餘余 於于 醜丑 復复 複复 曆历 纔才 藉借 鬍胡 幹干 薑姜 徵征 瑯琅
+`
+
+// Phrases converted to Traditional before characters, for ambiguous Simplified characters.
+var theS2TPhrases = `
+游戏遊戲 后来後來 以后以後 之后之後 最后最後 然后然後 前后前後 背后背後 后天後天 后面後面 后宫後宮 后会後會
+头发頭髮 理发理髮 白发白髮 干杯乾杯 干净乾淨 面条麵條 奋斗奮鬥 战斗戰鬥 决斗決鬥 台湾臺灣 台风颱風 一只一隻
+里面裡面 这里這裡 那里那裡 哪里哪裡 心里心裡 家里家裡 夜里夜裡 复仇復仇 复活復活 恢复恢復 复兴復興 周末週末
+钟表鐘錶 钟声鐘聲 丑闻醜聞 于是於是 余生餘生 其余其餘 关系關係 干部幹部 能干能幹 范围範圍 模范模範
+`
+
+// Pinyin of Simplified characters without tones, one reading for each character.
+// Polyphonic characters take the reading usually used in names.
+var theCharPinyins = []string{
+	"a 阿啊",
+	"ai 爱哀埃挨矮艾碍癌蔼霭",
+	"an 安按暗岸案俺鞍氨庵黯",
+	"ang 昂",
+	"ao 奥傲澳熬凹敖翱袄遨",
+	"ba 八巴把爸吧拔霸罢坝芭扒靶疤",
+	"bai 白百败拜摆柏佰",
+	"ban 半办班般板版搬伴扮斑颁瓣",
+	"bang 帮邦棒榜傍膀绑磅谤",
+	"bao 包宝保报抱暴爆饱薄堡豹胞鲍褒",
+	"bei 北被备背杯悲贝辈倍碑卑蓓",
+	"ben 本奔笨苯",
+	"beng 崩绷蹦泵",
+	"bi 比必毕闭笔避壁鼻彼币碧臂逼弊蔽毙庇痹",
+	"bian 边变便编遍辩鞭辨贬扁卞",
+	"biao 表标彪膘镖飙",
+	"bie 别憋鳖瘪",
+	"bin 宾滨彬斌濒缤鬓",
+	"bing 并病兵冰丙饼柄秉炳",
+	"bo 波博播伯拨勃驳泊搏玻剥脖舶菠",
+	"bu 不部步布补捕卜哺埠簿怖",
+	"ca 擦",
+	"cai 才采菜财材彩猜裁蔡踩",
+	"can 参残餐惨灿蚕惭",
+	"cang 藏仓苍舱沧",
+	"cao 草操曹槽糙",
+	"ce 策测册侧厕",
+	"cen 岑",
+	"ceng 层曾",
+	"cha 查茶差插察叉茬岔诧",
+	"chai 柴拆豺",
+	"chan 产缠禅蝉颤铲阐馋",
+	"chang 长常场唱厂尝肠偿畅昌倡敞",
+	"chao 超朝潮炒抄巢吵钞",
+	"che 车彻撤扯澈",
+	"chen 陈沉晨尘臣衬趁辰忱宸",
+	"cheng 成城程称承诚乘呈惩澄橙逞撑",
+	"chi 吃持迟池尺赤齿驰耻翅斥痴",
+	"chong 重冲虫充崇宠",
+	"chou 抽仇愁丑臭筹酬绸瞅",
+	"chu 出处初除楚础储触厨畜锄雏",
+	"chuan 传船穿川串喘",
+	"chuang 创床窗闯疮",
+	"chui 吹垂锤炊",
+	"chun 春纯唇醇蠢",
+	"chuo 戳绰",
+	"ci 次此词刺辞慈磁瓷赐雌",
+	"cong 从丛聪葱匆",
+	"cou 凑",
+	"cu 粗促醋簇",
+	"cuan 窜篡",
+	"cui 崔催脆翠摧粹",
+	"cun 村存寸",
+	"cuo 错措挫搓",
+	"da 大打达答搭",
+	"dai 代带待戴袋贷呆逮殆黛",
+	"dan 但单担丹胆淡蛋弹诞旦耽",
+	"dang 当党档挡荡",
+	"dao 道到刀导倒岛盗稻蹈悼",
+	"de 的得德",
+	"deng 等灯登邓瞪凳",
+	"di 地第低敌底帝弟递滴迪抵笛蒂缔堤",
+	"dian 点电店典殿垫淀甸颠巅",
+	"diao 调掉吊钓雕刁凋",
+	"die 跌叠蝶爹碟谍",
+	"ding 定顶丁订盯钉鼎",
+	"diu 丢",
+	"dong 东动冬懂洞冻董栋",
+	"dou 都斗豆抖逗陡兜",
+	"du 度读独毒杜堵渡肚督赌镀妒",
+	"duan 段断短端锻",
+	"dui 对队堆兑",
+	"dun 顿盾吨蹲敦",
+	"duo 多夺朵躲堕舵",
+	"e 额饿恶俄鹅娥峨厄扼",
+	"en 恩",
+	"er 二而儿耳尔",
+	"fa 发法罚伐乏阀",
+	"fan 反饭犯范翻凡繁烦返泛帆番藩",
+	"fang 方放房防访仿芳纺妨",
+	"fei 非飞费肥废菲肺匪沸妃诽",
+	"fen 分份粉奋纷愤坟芬焚",
+	"feng 风封丰峰疯锋冯奉逢缝凤蜂",
+	"fo 佛",
+	"fou 否",
+	"fu 复父夫服福富付府副负妇符扶浮幅辅赴腐伏抚俘腹覆傅芙",
+	"ga 嘎",
+	"gai 该改盖概钙溉",
+	"gan 干感敢赶甘肝杆竿",
+	"gang 刚港钢岗纲缸",
+	"gao 高告搞稿糕膏",
+	"ge 个各歌格哥割革隔阁戈鸽葛",
+	"gei 给",
+	"gen 根跟",
+	"geng 更耕耿",
+	"gong 工公共功攻宫供恭弓巩贡",
+	"gou 够沟构购狗钩勾",
+	"gu 古故顾股骨鼓谷固孤姑估雇菇",
+	"gua 挂瓜刮寡",
+	"guai 怪乖拐",
+	"guan 关官管观馆惯冠贯灌罐",
+	"guang 光广逛",
+	"gui 贵归规鬼桂柜轨跪龟瑰",
+	"gun 滚棍",
+	"guo 国过果锅郭裹",
+	"ha 哈",
+	"hai 还海害孩亥骇",
+	"han 汉含寒韩汗喊罕旱憾翰涵函",
+	"hang 航杭",
+	"hao 好号豪毫浩耗郝昊",
+	"he 和合河何核喝贺荷盒赫鹤",
+	"hei 黑嘿",
+	"hen 很恨狠痕",
+	"heng 横恒衡哼",
+	"hong 红洪宏鸿虹轰哄烘",
+	"hou 后候厚侯猴吼",
+	"hu 湖呼户护虎胡互忽乎壶狐糊蝴弧",
+	"hua 话华化画花划滑哗",
+	"huai 坏怀淮槐",
+	"huan 换欢环缓幻患唤焕嬛",
+	"huang 黄皇荒慌晃煌凰谎",
+	"hui 会回汇灰挥辉毁悔徽惠慧绘",
+	"hun 婚混魂昏浑",
+	"huo 活火或货获伙祸霍",
+	"ji 机几及级极记计集基急即技纪际继积击济挤鸡季既寄疾籍迹激吉姬忌",
+	"jia 家加价假架甲佳驾嘉夹贾",
+	"jian 见间建件简剑坚渐减检健尖舰剪监箭键鉴兼肩艰煎践",
+	"jiang 将江讲降奖疆姜蒋匠僵酱",
+	"jiao 教交较脚角叫焦胶郊骄娇搅缴",
+	"jie 结解界接节街姐介借阶届杰洁截戒劫揭",
+	"jin 进金近今尽紧仅禁劲锦津晋巾筋瑾",
+	"jing 经京精境静警竟镜景径井敬惊晶净竞",
+	"jiong 窘炯",
+	"jiu 就九酒久旧救究纠舅",
+	"ju 局举具据聚巨剧居菊拒距句鞠",
+	"juan 卷捐娟倦眷",
+	"jue 决绝觉掘爵诀倔",
+	"jun 军君均俊峻骏菌",
+	"ka 卡咖",
+	"kai 开凯慨楷铠",
+	"kan 看刊砍堪侃",
+	"kang 康抗扛",
+	"kao 考靠烤",
+	"ke 可科克客课刻渴颗壳柯棵",
+	"ken 肯恳啃",
+	"keng 坑",
+	"kong 空控孔恐",
+	"kou 口扣寇",
+	"ku 苦库哭酷枯裤",
+	"kua 夸跨垮",
+	"kuai 快块筷",
+	"kuan 宽款",
+	"kuang 况狂矿框旷",
+	"kui 亏愧魁溃葵",
+	"kun 困昆坤捆",
+	"kuo 扩括阔",
+	"la 拉啦辣蜡腊",
+	"lai 来赖莱",
+	"lan 蓝兰烂栏拦篮懒览滥澜岚",
+	"lang 浪狼郎朗廊琅",
+	"lao 老劳牢姥捞",
+	"le 了乐勒",
+	"lei 类雷泪累垒磊蕾",
+	"leng 冷愣",
+	"li 理里力利立李历离例礼丽黎厉励粒璃梨莉狸哩",
+	"lian 连联脸练恋莲帘怜链廉炼",
+	"liang 两量亮良凉梁粮谅辆",
+	"liao 料疗聊辽僚燎",
+	"lie 列烈裂猎劣",
+	"lin 林临邻琳霖淋鳞麟",
+	"ling 领另令灵零龄岭凌玲铃陵",
+	"liu 六流留刘柳溜硫",
+	"long 龙隆笼拢聋",
+	"lou 楼漏陋搂",
+	"lu 路陆录鹿卢炉露鲁芦",
+	"lv 律绿旅率虑履驴吕侣",
+	"luan 乱卵",
+	"lue 略掠",
+	"lun 论轮伦",
+	"luo 落罗洛络逻骆螺萝珞",
+	"ma 吗妈马码麻骂嘛玛",
+	"mai 买卖麦埋迈脉",
+	"man 满慢漫曼蛮",
+	"mang 忙芒盲茫莽",
+	"mao 毛猫冒帽貌矛茂贸",
+	"me 么",
+	"mei 没美每妹梅媒煤眉魅玫",
+	"men 们门闷",
+	"meng 梦蒙猛盟孟萌",
+	"mi 米密秘迷蜜谜弥芈",
+	"mian 面免棉眠绵",
+	"miao 秒妙苗庙描瞄淼",
+	"mie 灭蔑",
+	"min 民敏闽",
+	"ming 名明命鸣铭冥",
+	"mo 模末莫默魔磨摩墨膜陌漠",
+	"mou 某谋",
+	"mu 目木母亩幕牧墓慕穆暮",
+	"na 那拿哪纳娜",
+	"nai 乃奶耐奈",
+	"nan 南难男楠",
+	"nang 囊",
+	"nao 脑闹恼",
+	"ne 呢",
+	"nei 内",
+	"nen 嫩",
+	"neng 能",
+	"ni 你尼泥逆拟妮倪",
+	"nian 年念",
+	"niang 娘",
+	"niao 鸟",
+	"nie 聂",
+	"nin 您",
+	"ning 宁凝",
+	"niu 牛扭纽",
+	"nong 农弄浓",
+	"nu 努怒奴",
+	"nv 女",
+	"nuan 暖",
+	"nuo 诺挪",
+	"o 哦",
+	"ou 欧偶",
+	"pa 怕爬帕",
+	"pai 派排拍牌",
+	"pan 判盘盼攀潘",
+	"pang 旁胖庞",
+	"pao 跑炮泡抛袍",
+	"pei 配培陪佩赔裴",
+	"pen 喷盆",
+	"peng 朋碰彭鹏棚蓬膨",
+	"pi 批皮匹披疲脾辟",
+	"pian 片篇偏骗",
+	"piao 票漂飘",
+	"pin 品频拼贫",
+	"ping 平评瓶凭苹屏萍",
+	"po 破迫坡婆泼颇",
+	"pu 普铺扑葡朴谱浦",
+	"qi 起其期七气器汽奇齐企骑旗启妻弃漆欺戚棋祈琪",
+	"qia 恰洽",
+	"qian 前千钱签迁浅潜欠牵铅谦",
+	"qiang 强枪墙抢腔",
+	"qiao 桥巧敲乔悄瞧",
+	"qie 且切窃",
+	"qin 亲琴秦勤侵禽",
+	"qing 情青清请轻庆倾晴卿",
+	"qiong 穷琼",
+	"qiu 求球秋丘囚",
+	"qu 去取区曲趣渠屈驱",
+	"quan 全权泉劝圈拳犬",
+	"que 却确缺雀鹊",
+	"qun 群裙",
+	"ran 然燃染冉",
+	"rang 让嚷",
+	"rao 绕扰饶",
+	"re 热惹",
+	"ren 人任认仁忍刃",
+	"reng 仍扔",
+	"ri 日",
+	"rong 容荣融绒溶蓉嵘",
+	"rou 肉柔揉",
+	"ru 如入乳儒辱",
+	"ruan 软阮",
+	"rui 瑞锐蕊",
+	"run 润闰",
+	"ruo 若弱",
+	"sa 撒萨洒",
+	"sai 赛塞",
+	"san 三散伞",
+	"sang 桑丧",
+	"sao 扫嫂骚",
+	"se 色涩瑟",
+	"sen 森",
+	"sha 杀沙傻纱刹",
+	"shai 晒",
+	"shan 山善闪扇衫陕杉珊",
+	"shang 上商伤尚赏觞",
+	"shao 少烧稍绍勺哨邵",
+	"she 设社射舍蛇涉摄",
+	"shei 谁",
+	"shen 什深身神审甚沈伸慎渗申",
+	"sheng 生声胜省圣升盛绳剩笙",
+	"shi 是时事十实使世市识始式师室诗史石食失施示士视试势适释拾湿狮誓尸",
+	"shou 手收受首守授售瘦兽寿",
+	"shu 书数术属树输束述熟叔殊舒鼠蜀淑疏",
+	"shua 刷耍",
+	"shuai 帅衰摔",
+	"shuan 拴",
+	"shuang 双霜爽",
+	"shui 水税睡",
+	"shun 顺瞬",
+	"shuo 说硕",
+	"si 四死思司丝私斯寺似撕",
+	"song 送宋松颂",
+	"sou 搜艘",
+	"su 素速诉苏宿塑俗",
+	"suan 算酸蒜",
+	"sui 虽随岁碎隋遂",
+	"sun 孙损",
+	"suo 所索锁缩",
+	"ta 他她它塔踏",
+	"tai 太台态泰胎",
+	"tan 谈探叹坦贪滩潭",
+	"tang 堂唐糖汤躺塘",
+	"tao 套逃讨陶桃涛韬",
+	"te 特",
+	"teng 腾疼藤",
+	"ti 提题体替梯踢",
+	"tian 天田甜填",
+	"tiao 条跳挑",
+	"tie 铁贴",
+	"ting 听停庭挺厅亭婷",
+	"tong 同通统童痛铜桶",
+	"tou 头投偷透",
+	"tu 图土突途徒屠兔涂",
+	"tuan 团",
+	"tui 推退腿",
+	"tun 吞屯",
+	"tuo 脱托拖妥驼",
+	"wa 瓦挖蛙娃",
+	"wai 外歪",
+	"wan 万完晚玩湾碗弯婉",
+	"wang 王往网忘望旺亡汪",
+	"wei 为位未委维卫围威微伟唯危味尾伪魏韦",
+	"wen 文问温闻稳纹吻",
+	"weng 翁",
+	"wo 我握卧窝沃",
+	"wu 无五物务武午舞误屋吴乌雾悟伍",
+	"xi 西系习细喜戏席息希洗析惜袭吸溪熙夕曦",
+	"xia 下夏侠峡瞎虾霞",
+	"xian 现先线县限显险仙鲜献闲陷贤咸",
+	"xiang 想向相象香乡项响像详箱湘祥翔",
+	"xiao 小笑校效消晓销萧肖孝箫骁",
+	"xie 些写谢协鞋斜邪械",
+	"xin 新心信欣辛薪馨鑫",
+	"xing 行性星形型兴醒幸刑姓",
+	"xiong 雄兄凶熊胸",
+	"xiu 修秀休袖绣",
+	"xu 许需须续序虚徐叙绪",
+	"xuan 选宣悬旋玄轩萱瑄",
+	"xue 学雪血穴薛",
+	"xun 寻训讯迅巡询逊",
+	"ya 压亚牙呀雅鸭崖琊",
+	"yan 眼言严研演烟沿延颜验燕岩盐炎艳宴焰琰嫣妍",
+	"yang 样阳养洋羊杨央仰",
+	"yao 要药摇腰遥妖邀姚耀",
+	"ye 也业夜叶爷野页液",
+	"yi 一以已意义议易医依亿益艺移异忆衣疑遗仪宜译役翼伊毅裔懿",
+	"yin 因引音银印饮阴隐尹殷",
+	"ying 应英影营迎硬映赢鹰樱莹婴璎",
+	"yo 哟",
+	"yong 用永勇拥涌庸",
+	"you 有由又友游油优右幽尤忧犹悠邮",
+	"yu 于与语育遇雨鱼玉余欲预域宇羽愈狱誉郁裕渔娱虞瑜",
+	"yuan 元员原院远源愿园圆缘援怨苑袁渊媛",
+	"yue 月越约跃阅岳悦粤玥",
+	"yun 云运允孕韵晕",
+	"za 杂砸",
+	"zai 在再载灾宰",
+	"zan 赞咱暂",
+	"zang 脏葬",
+	"zao 早造遭糟澡枣燥",
+	"ze 则责泽择",
+	"zei 贼",
+	"zen 怎",
+	"zeng 增赠",
+	"zha 扎炸诈闸",
+	"zhai 宅摘窄债",
+	"zhan 战站展占斩盏沾詹",
+	"zhang 张章掌丈仗障涨彰",
+	"zhao 找照招召赵兆",
+	"zhe 这者着折哲浙遮",
+	"zhen 真镇阵针珍震振侦甄",
+	"zheng 正政证争整征郑症蒸挣峥",
+	"zhi 之只知制至直治指支志职纸值植执质智置致织止枝",
+	"zhong 中种众终钟忠仲",
+	"zhou 周州洲舟宙皱骤",
+	"zhu 主住注助著诸珠猪竹逐朱祝筑驻",
+	"zhua 抓",
+	"zhuan 转专砖赚",
+	"zhuang 装状庄壮撞妆",
+	"zhui 追坠",
+	"zhun 准",
+	"zhuo 捉桌卓拙",
+	"zi 子自字资紫姿",
+	"zong 总宗综纵踪",
+	"zou 走奏邹",
+	"zu 组族足祖阻租",
+	"zuan 钻",
+	"zui 最罪醉嘴",
+	"zun 尊遵",
+	"zuo 作做坐左座昨",
+}
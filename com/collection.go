@@ -0,0 +1,368 @@
+package com
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+)
+
+const (
+	// collection file version
+	collectionFileVersion1Initial    uint32 = 0x01
+	collectionFileVersion2LargeFiles uint32 = 0x02
+
+	// tag type
+	collectionTagTypeHash      byte = 0x01
+	collectionTagTypeString    byte = 0x02
+	collectionTagTypeUint32    byte = 0x03
+	collectionTagTypeFloat32   byte = 0x04
+	collectionTagTypeBool      byte = 0x05
+	collectionTagTypeBoolArray byte = 0x06
+	collectionTagTypeBlob      byte = 0x07
+	collectionTagTypeUint16    byte = 0x08
+	collectionTagTypeUint8     byte = 0x09
+	collectionTagTypeBsob      byte = 0x0A
+	collectionTagTypeUint64    byte = 0x0B
+	collectionTagTypeStr1      byte = 0x11 // string with fixed size from 1 to 16
+	collectionTagTypeStr16     byte = 0x20
+
+	// tag name
+	collectionTagFileName    byte = 0x01
+	collectionTagFileSize    byte = 0x02
+	collectionTagAICHHash    byte = 0x27
+	collectionTagFileHash    byte = 0x28
+	collectionTagAuthor      byte = 0x31
+	collectionTagFileComment byte = 0xF6
+	collectionTagFileRating  byte = 0xF7
+
+	// new ED2K tag format, name is one byte ID
+	collectionTagNewFormat byte = 0x80
+
+	maxCollectionFileNbr = 100 * 1000
+)
+
+// CollectionExt is file extension of eMule collection.
+const CollectionExt = ".emulecollection"
+
+// Collection is eMule collection file, which is used to hand off batch of file links.
+type Collection struct {
+	Name      string
+	Author    string
+	FileLinks []*Ed2kFileLink
+}
+
+// NewCollection x
+func NewCollection(name string, fileLinks []*Ed2kFileLink) *Collection {
+	return &Collection{Name: name, FileLinks: fileLinks}
+}
+
+// WriteBinary is writing collection in eMule binary format.
+func (c *Collection) WriteBinary(w io.Writer) error {
+	buf := bytes.Buffer{}
+
+	binary.Write(&buf, binary.LittleEndian, collectionFileVersion2LargeFiles)
+
+	// collection tags
+	var tagCount uint32 = 1
+	if c.Author != "" {
+		tagCount++
+	}
+	binary.Write(&buf, binary.LittleEndian, tagCount)
+	writeCollectionStringTag(&buf, collectionTagFileName, c.Name)
+	if c.Author != "" {
+		writeCollectionStringTag(&buf, collectionTagAuthor, c.Author)
+	}
+
+	// files
+	binary.Write(&buf, binary.LittleEndian, uint32(len(c.FileLinks)))
+	for _, fileLink := range c.FileLinks {
+		aich := GetAICHHashStr(fileLink.AICHHash)
+
+		var fileTagCount uint32 = 3
+		if aich != "" {
+			fileTagCount++
+		}
+		binary.Write(&buf, binary.LittleEndian, fileTagCount)
+
+		hash := ConvertEd2kHash32(fileLink.Hash)
+		writeCollectionTagHeader(&buf, collectionTagTypeHash, collectionTagFileHash)
+		buf.Write(hash[:])
+
+		writeCollectionTagHeader(&buf, collectionTagTypeUint64, collectionTagFileSize)
+		binary.Write(&buf, binary.LittleEndian, fileLink.Size)
+
+		writeCollectionStringTag(&buf, collectionTagFileName, fileLink.Name)
+
+		if aich != "" {
+			writeCollectionStringTag(&buf, collectionTagAICHHash, aich)
+		}
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// WriteText is writing collection in eMule text format, which is one ED2K link per line.
+func (c *Collection) WriteText(w io.Writer) error {
+	buf := bytes.Buffer{}
+	for _, fileLink := range c.FileLinks {
+		buf.WriteString(fileLink.GetEd2kLink())
+		buf.WriteString("\r\n")
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func writeCollectionTagHeader(buf *bytes.Buffer, byType byte, name byte) {
+	buf.WriteByte(byType)
+	binary.Write(buf, binary.LittleEndian, uint16(1))
+	buf.WriteByte(name)
+}
+
+func writeCollectionStringTag(buf *bytes.Buffer, name byte, value string) {
+	writeCollectionTagHeader(buf, collectionTagTypeString, name)
+	binary.Write(buf, binary.LittleEndian, uint16(len(value)))
+	buf.WriteString(value)
+}
+
+// ReadCollection is reading collection in eMule binary or text format.
+// Format is detected by file content.
+func ReadCollection(r io.Reader) (*Collection, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) >= 4 {
+		version := binary.LittleEndian.Uint32(data)
+		if version == collectionFileVersion1Initial || version == collectionFileVersion2LargeFiles {
+			return readBinaryCollection(data)
+		}
+	}
+
+	return readTextCollection(data)
+}
+
+func readTextCollection(data []byte) (*Collection, error) {
+	c := Collection{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		fileLink := ParseEd2kLink(line)
+		if fileLink == nil {
+			continue
+		}
+
+		c.FileLinks = append(c.FileLinks, fileLink)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(c.FileLinks) == 0 {
+		return nil, errors.New("no ED2K link in collection")
+	}
+
+	return &c, nil
+}
+
+func readBinaryCollection(data []byte) (*Collection, error) {
+	r := bytes.NewReader(data)
+	c := Collection{}
+
+	var version uint32
+	binary.Read(r, binary.LittleEndian, &version)
+
+	// collection tags
+	var tagCount uint32
+	if err := binary.Read(r, binary.LittleEndian, &tagCount); err != nil {
+		return nil, err
+	}
+	for ; tagCount > 0; tagCount-- {
+		tag, err := readCollectionTag(r)
+		if err != nil {
+			return nil, err
+		}
+
+		switch tag.name {
+		case collectionTagFileName:
+			c.Name, _ = tag.value.(string)
+		case collectionTagAuthor:
+			c.Author, _ = tag.value.(string)
+		}
+	}
+
+	// files
+	var fileCount uint32
+	if err := binary.Read(r, binary.LittleEndian, &fileCount); err != nil {
+		return nil, err
+	}
+	if fileCount > maxCollectionFileNbr {
+		return nil, errors.New("too many files in collection")
+	}
+
+	for ; fileCount > 0; fileCount-- {
+		if err := binary.Read(r, binary.LittleEndian, &tagCount); err != nil {
+			return nil, err
+		}
+
+		fileLink := Ed2kFileLink{FileInfo: FileInfo{Type: UnknownType, Season: -1, Episode: -1, EpisodeEnd: -1}}
+		for ; tagCount > 0; tagCount-- {
+			tag, err := readCollectionTag(r)
+			if err != nil {
+				return nil, err
+			}
+
+			switch tag.name {
+			case collectionTagFileHash:
+				if hash, ok := tag.value.([]byte); ok && len(hash) == 16 {
+					kadHash := ConvertEd2kHash32(hash)
+					fileLink.Hash = kadHash[:]
+				}
+			case collectionTagFileSize:
+				fileLink.Size = collectionTagUint64(tag.value)
+			case collectionTagFileName:
+				fileLink.Name, _ = tag.value.(string)
+			case collectionTagAICHHash:
+				aich, _ := tag.value.(string)
+				fileLink.AICHHash = ParseAICHHashStr(aich)
+			}
+		}
+
+		if fileLink.Hash == nil || fileLink.Name == "" {
+			return nil, errors.New("invalid file in collection")
+		}
+
+		c.FileLinks = append(c.FileLinks, &fileLink)
+	}
+
+	return &c, nil
+}
+
+type collectionTag struct {
+	name  byte // only tags with one byte name are useful for us
+	value interface{}
+}
+
+func readCollectionTag(r *bytes.Reader) (*collectionTag, error) {
+	byType, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	tag := collectionTag{}
+	if byType&collectionTagNewFormat != 0 {
+		byType &= ^collectionTagNewFormat
+		if tag.name, err = r.ReadByte(); err != nil {
+			return nil, err
+		}
+	} else {
+		var nameLen uint16
+		if err := binary.Read(r, binary.LittleEndian, &nameLen); err != nil {
+			return nil, err
+		}
+
+		name, err := readCollectionBytes(r, int(nameLen))
+		if err != nil {
+			return nil, err
+		}
+		if len(name) == 1 {
+			tag.name = name[0]
+		}
+	}
+
+	switch {
+	case byType == collectionTagTypeHash:
+		tag.value, err = readCollectionBytes(r, 16)
+	case byType == collectionTagTypeString:
+		var size uint16
+		if err = binary.Read(r, binary.LittleEndian, &size); err == nil {
+			var b []byte
+			b, err = readCollectionBytes(r, int(size))
+			tag.value = string(b)
+		}
+	case byType >= collectionTagTypeStr1 && byType <= collectionTagTypeStr16:
+		var b []byte
+		b, err = readCollectionBytes(r, int(byType-collectionTagTypeStr1)+1)
+		tag.value = string(b)
+	case byType == collectionTagTypeUint8 || byType == collectionTagTypeBool:
+		var v uint8
+		err = binary.Read(r, binary.LittleEndian, &v)
+		tag.value = v
+	case byType == collectionTagTypeUint16:
+		var v uint16
+		err = binary.Read(r, binary.LittleEndian, &v)
+		tag.value = v
+	case byType == collectionTagTypeUint32:
+		var v uint32
+		err = binary.Read(r, binary.LittleEndian, &v)
+		tag.value = v
+	case byType == collectionTagTypeUint64:
+		var v uint64
+		err = binary.Read(r, binary.LittleEndian, &v)
+		tag.value = v
+	case byType == collectionTagTypeFloat32:
+		var v float32
+		err = binary.Read(r, binary.LittleEndian, &v)
+		tag.value = v
+	case byType == collectionTagTypeBoolArray:
+		var bits uint16
+		if err = binary.Read(r, binary.LittleEndian, &bits); err == nil {
+			tag.value, err = readCollectionBytes(r, int(bits)/8+1)
+		}
+	case byType == collectionTagTypeBlob:
+		var size uint32
+		if err = binary.Read(r, binary.LittleEndian, &size); err == nil {
+			tag.value, err = readCollectionBytes(r, int(size))
+		}
+	case byType == collectionTagTypeBsob:
+		var size uint8
+		if err = binary.Read(r, binary.LittleEndian, &size); err == nil {
+			tag.value, err = readCollectionBytes(r, int(size))
+		}
+	default:
+		return nil, errors.New("unknown tag type in collection")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &tag, nil
+}
+
+func readCollectionBytes(r *bytes.Reader, n int) ([]byte, error) {
+	if n > r.Len() {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	b := make([]byte, n)
+	_, err := io.ReadFull(r, b)
+	return b, err
+}
+
+func collectionTagUint64(value interface{}) uint64 {
+	switch v := value.(type) {
+	case uint64:
+		return v
+	case uint32:
+		return uint64(v)
+	case uint16:
+		return uint64(v)
+	case uint8:
+		return uint64(v)
+	}
+
+	return 0
+}
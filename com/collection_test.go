@@ -0,0 +1,99 @@
+package com
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+// Fixtures are built to eMule's collection format by hand, not exported by eMule:
+// collection.emulecollection has tags in old format with uint32 size and in new format with uint64 size.
+var collectionTestLinks = []string{
+	"ed2k://|file|The.Walking.Dead.S01E01.720p.mkv|734003200|31D6CFE0D16AE931B73C59D7E0C089C0|/",
+	"ed2k://|file|%E8%A1%8C%E5%B0%B8%E8%B5%B0%E8%82%89.S01E02.1080p.mkv|5368709120|0123456789ABCDEF0123456789ABCDEF|h=AAAQEAYEAUDAOCAJBIFQYDIOB4IBCEQT|/",
+}
+
+func readTestCollection(t *testing.T, fileName string) *Collection {
+	f, err := os.Open(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	c, err := ReadCollection(f)
+	if err != nil {
+		t.Fatalf("ReadCollection(%s) failed: %s", fileName, err)
+	}
+
+	return c
+}
+
+func checkCollectionLinks(t *testing.T, prefix string, c *Collection) {
+	if len(c.FileLinks) != len(collectionTestLinks) {
+		t.Fatalf("%s: %d files, want %d", prefix, len(c.FileLinks), len(collectionTestLinks))
+	}
+
+	for i, fileLink := range c.FileLinks {
+		if link := fileLink.GetEd2kLink(); link != collectionTestLinks[i] {
+			t.Errorf("%s: file %d is %s, want %s", prefix, i, link, collectionTestLinks[i])
+		}
+	}
+}
+
+func TestReadCollection(t *testing.T) {
+	c := readTestCollection(t, "testdata/collection.emulecollection")
+	if c.Name != "行尸走肉 第一季" || c.Author != "hahajing" {
+		t.Errorf("binary collection is %s by %s", c.Name, c.Author)
+	}
+	checkCollectionLinks(t, "binary", c)
+
+	checkCollectionLinks(t, "text", readTestCollection(t, "testdata/collection_text.emulecollection"))
+}
+
+func TestCollectionRoundTrip(t *testing.T) {
+	c := readTestCollection(t, "testdata/collection.emulecollection")
+
+	var buf bytes.Buffer
+	if err := c.WriteBinary(&buf); err != nil {
+		t.Fatal(err)
+	}
+	binaryC, err := ReadCollection(&buf)
+	if err != nil {
+		t.Fatalf("read written binary failed: %s", err)
+	}
+	if binaryC.Name != c.Name || binaryC.Author != c.Author {
+		t.Errorf("written binary collection is %s by %s, want %s by %s", binaryC.Name, binaryC.Author, c.Name, c.Author)
+	}
+	checkCollectionLinks(t, "binary round trip", binaryC)
+
+	buf.Reset()
+	if err := c.WriteText(&buf); err != nil {
+		t.Fatal(err)
+	}
+	textC, err := ReadCollection(&buf)
+	if err != nil {
+		t.Fatalf("read written text failed: %s", err)
+	}
+	checkCollectionLinks(t, "text round trip", textC)
+}
+
+func TestReadBadCollection(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/collection.emulecollection")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := [][]byte{
+		nil,
+		[]byte("not a collection\r\n"),
+		data[:len(data)-10], // truncated
+		{0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xff, 0xff, 0xff, 0xff}, // too many files
+	}
+
+	for i, data := range tests {
+		if c, err := ReadCollection(bytes.NewReader(data)); err == nil {
+			t.Errorf("%d: ReadCollection got %+v, want error", i, c)
+		}
+	}
+}
@@ -0,0 +1,333 @@
+package com
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+const contentFilterCheckTime = 30 // second, for reloading modified rule file
+
+// FilterFile is file checked by content filter.
+type FilterFile struct {
+	Name string
+	Size uint64
+	Type string
+	Hash []byte // KAD order like Ed2kFileLink
+}
+
+// FilterReason is why file is dropped.
+type FilterReason struct {
+	Rule   string // keyword, regexp, script, size, type or hash
+	Detail string
+}
+
+func (r *FilterReason) String() string {
+	return r.Rule + ": " + r.Detail
+}
+
+// ContentFilter x
+type ContentFilter interface {
+	// Check returns reason if file should be dropped, nil if passed.
+	Check(file *FilterFile) *FilterReason
+}
+
+// FilterRules is rules of one filter profile, in JSON file config/filter/<profile>.json.
+type FilterRules struct {
+	Keywords       []string   // dropped if name contains any one, case insensitive
+	KeywordGroups  [][]string // dropped if name contains all of one group
+	Regexps        []string   // dropped if name matches any one
+	AllowedScripts []string   // Unicode scripts allowed besides Latin-1 and common punctuations, e.g. Han, Hiragana, Hangul. Empty means all.
+	MinSize        uint64     // 0 means no limit
+	MaxSize        uint64     // 0 means no limit
+	BlockedTypes   []string   // ED2K file types, e.g. Pro
+	BlockedHashes  []string   // ED2K hashes in hex
+}
+
+// built-in rules if no profile loaded
+var defaultFilterRules = FilterRules{
+	Keywords: []string{
+		"性交", "做爱", "打炮", "无码", "有码", "淫", "偷拍", "中出", "熟女", "巨乳", "人妻",
+		"無碼", "有碼",
+		"sex", "gay",
+	},
+	KeywordGroups:  [][]string{{"tokyo", "hot"}},
+	AllowedScripts: []string{"Han"},
+}
+
+// RuleFilter is content filter by rules. If it's loaded from file, it's reloaded after file modified.
+type RuleFilter struct {
+	fileName  string // empty if not from file
+	tModified time.Time
+
+	rules    *FilterRules
+	regexps  []*regexp.Regexp
+	scripts  map[string]*unicode.RangeTable
+	hashMap  map[string]bool // upper case hex
+	typeMap  map[string]bool
+	keywords []string // lower case
+
+	lock sync.RWMutex
+}
+
+// NewRuleFilter x
+func NewRuleFilter(rules *FilterRules) (*RuleFilter, error) {
+	f := RuleFilter{}
+	if err := f.setRules(rules, time.Time{}); err != nil {
+		return nil, err
+	}
+
+	return &f, nil
+}
+
+// LoadRuleFilter is loading rules from JSON file.
+func LoadRuleFilter(fileName string) (*RuleFilter, error) {
+	f := RuleFilter{fileName: fileName}
+	if err := f.Reload(); err != nil {
+		return nil, err
+	}
+
+	return &f, nil
+}
+
+// GetFilterProfileFileName x
+func GetFilterProfileFileName(profile string) string {
+	return GetConfigPath() + "/config/filter/" + profile + ".json"
+}
+
+func (f *RuleFilter) setRules(rules *FilterRules, tModified time.Time) error {
+	var regexps []*regexp.Regexp
+	for _, s := range rules.Regexps {
+		re, err := regexp.Compile("(?i)" + s)
+		if err != nil {
+			return err
+		}
+		regexps = append(regexps, re)
+	}
+
+	scripts := make(map[string]*unicode.RangeTable)
+	for _, name := range rules.AllowedScripts {
+		table, ok := unicode.Scripts[name]
+		if !ok {
+			return fmt.Errorf("unknown script: %s", name)
+		}
+		scripts[name] = table
+	}
+
+	hashMap := make(map[string]bool)
+	for _, s := range rules.BlockedHashes {
+		hash, err := hex.DecodeString(s)
+		if err != nil || len(hash) != 16 {
+			return fmt.Errorf("invalid hash: %s", s)
+		}
+		hashMap[strings.ToUpper(s)] = true
+	}
+
+	typeMap := make(map[string]bool)
+	for _, s := range rules.BlockedTypes {
+		typeMap[strings.ToLower(s)] = true
+	}
+
+	var keywords []string
+	for _, s := range rules.Keywords {
+		keywords = append(keywords, strings.ToLower(s))
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.rules = rules
+	f.regexps = regexps
+	f.scripts = scripts
+	f.hashMap = hashMap
+	f.typeMap = typeMap
+	f.keywords = keywords
+	f.tModified = tModified
+
+	return nil
+}
+
+// Reload is reloading rules from file, rules are not changed if failed.
+func (f *RuleFilter) Reload() error {
+	if f.fileName == "" {
+		return nil
+	}
+
+	info, err := os.Stat(f.fileName)
+	if err != nil {
+		return err
+	}
+
+	data, err := ioutil.ReadFile(f.fileName)
+	if err != nil {
+		return err
+	}
+
+	var rules FilterRules
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return err
+	}
+
+	if err := f.setRules(&rules, info.ModTime()); err != nil {
+		return err
+	}
+
+	HhjLog.Infof("Content filter loaded: %s", f.fileName)
+
+	return nil
+}
+
+func (f *RuleFilter) reloadRoutine() {
+	for {
+		time.Sleep(contentFilterCheckTime * time.Second)
+
+		f.lock.RLock()
+		tModified := f.tModified
+		f.lock.RUnlock()
+
+		info, err := os.Stat(f.fileName)
+		if err != nil || !info.ModTime().After(tModified) {
+			continue
+		}
+
+		if err := f.Reload(); err != nil {
+			HhjLog.Criticalf("Reload content filter failed: %s", err)
+
+			// don't retry until modified again
+			f.lock.Lock()
+			f.tModified = info.ModTime()
+			f.lock.Unlock()
+		}
+	}
+}
+
+// Check x
+func (f *RuleFilter) Check(file *FilterFile) *FilterReason {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+
+	name := strings.ToLower(file.Name)
+
+	if len(f.hashMap) > 0 && len(file.Hash) == 16 {
+		hash := ConvertEd2kHash32(file.Hash)
+		hashStr := strings.ToUpper(hex.EncodeToString(hash[:]))
+		if f.hashMap[hashStr] {
+			return &FilterReason{Rule: "hash", Detail: hashStr}
+		}
+	}
+
+	if f.typeMap[strings.ToLower(file.Type)] {
+		return &FilterReason{Rule: "type", Detail: file.Type}
+	}
+
+	if file.Size < f.rules.MinSize || (f.rules.MaxSize != 0 && file.Size > f.rules.MaxSize) {
+		return &FilterReason{Rule: "size", Detail: fmt.Sprintf("%d", file.Size)}
+	}
+
+	if len(f.scripts) > 0 {
+		for _, c := range name {
+			if !f.isAllowedChar(c) {
+				return &FilterReason{Rule: "script", Detail: string(c)}
+			}
+		}
+	}
+
+	for _, key := range f.keywords {
+		if strings.Index(name, key) != -1 {
+			return &FilterReason{Rule: "keyword", Detail: key}
+		}
+	}
+
+	for _, keys := range f.rules.KeywordGroups {
+		matched := true
+		for _, key := range keys {
+			if strings.Index(name, strings.ToLower(key)) == -1 {
+				matched = false
+				break
+			}
+		}
+
+		if matched {
+			return &FilterReason{Rule: "keyword", Detail: strings.Join(keys, " ")}
+		}
+	}
+
+	for _, re := range f.regexps {
+		if re.MatchString(file.Name) {
+			return &FilterReason{Rule: "regexp", Detail: re.String()}
+		}
+	}
+
+	return nil
+}
+
+func (f *RuleFilter) isAllowedChar(c rune) bool {
+	if c <= 255 || unicode.In(c, unicode.Common, unicode.Inherited) {
+		return true
+	}
+
+	for _, table := range f.scripts {
+		if unicode.Is(table, c) {
+			return true
+		}
+	}
+
+	return false
+}
+
+var theContentFilter ContentFilter
+var theContentFilterLock sync.RWMutex
+
+func init() {
+	theContentFilter, _ = NewRuleFilter(&defaultFilterRules)
+}
+
+// SetContentFilter is replacing content filter used by KAD search.
+func SetContentFilter(f ContentFilter) {
+	theContentFilterLock.Lock()
+	defer theContentFilterLock.Unlock()
+
+	theContentFilter = f
+}
+
+// StartContentFilter is loading rules of profile, which are reloaded after file modified.
+// Built-in rules are still used if failed.
+func StartContentFilter(profile string) error {
+	f, err := LoadRuleFilter(GetFilterProfileFileName(profile))
+	if err != nil {
+		return err
+	}
+
+	SetContentFilter(f)
+	go f.reloadRoutine()
+
+	return nil
+}
+
+// ReloadContentFilter is reloading content filter at once if it's loaded from file.
+func ReloadContentFilter() error {
+	theContentFilterLock.RLock()
+	f, ok := theContentFilter.(*RuleFilter)
+	theContentFilterLock.RUnlock()
+
+	if !ok {
+		return nil
+	}
+
+	return f.Reload()
+}
+
+// CheckContent is checking file by content filter, reason is returned if it should be dropped.
+func CheckContent(file *FilterFile) *FilterReason {
+	theContentFilterLock.RLock()
+	defer theContentFilterLock.RUnlock()
+
+	return theContentFilter.Check(file)
+}
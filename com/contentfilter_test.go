@@ -0,0 +1,95 @@
+package com
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFilterProfiles(t *testing.T) {
+	tests := []struct {
+		name     string
+		wantRule map[string]string // rule of each profile, empty if passed
+	}{
+		{"The.Walking.Dead.S01E01.720p.mkv", map[string]string{"default": "", "cjk": ""}},
+		{"琅琊榜第01集[国语中字].rmvb", map[string]string{"default": "", "cjk": ""}},
+		{"Amélie.2001.1080p.mkv", map[string]string{"default": "", "cjk": ""}},
+		{"Tokyo.Hot.n0001.avi", map[string]string{"default": "keyword", "cjk": "keyword"}},
+		{"无码高清.avi", map[string]string{"default": "keyword", "cjk": "keyword"}},
+		{"GAY.avi", map[string]string{"default": "keyword", "cjk": "keyword"}},
+
+		// other scripts are only allowed by cjk
+		{"となりのトトロ.mkv", map[string]string{"default": "script", "cjk": ""}},
+		{"기생충.2019.mkv", map[string]string{"default": "script", "cjk": ""}},
+		{"Брат.1997.avi", map[string]string{"default": "script", "cjk": ""}},
+		{"อีก.avi", map[string]string{"default": "script", "cjk": "script"}},
+		{"アダルト動画.avi", map[string]string{"default": "script", "cjk": "keyword"}},
+		{"야동.avi", map[string]string{"default": "script", "cjk": "keyword"}},
+	}
+
+	for _, profile := range []string{"default", "cjk"} {
+		f, err := LoadRuleFilter("../config/filter/" + profile + ".json")
+		if err != nil {
+			t.Fatalf("load %s failed: %s", profile, err)
+		}
+
+		for _, test := range tests {
+			rule := ""
+			if reason := f.Check(&FilterFile{Name: test.name, Type: Ed2kFileTypeVideo}); reason != nil {
+				rule = reason.Rule
+			}
+			if rule != test.wantRule[profile] {
+				t.Errorf("%s: Check(%s) is %q, want %q", profile, test.name, rule, test.wantRule[profile])
+			}
+		}
+	}
+}
+
+// default.json is same as built-in rules used before it's loaded.
+func TestDefaultFilterProfile(t *testing.T) {
+	f, err := LoadRuleFilter("../config/filter/default.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rules := *f.rules
+	rules.Regexps, rules.BlockedTypes, rules.BlockedHashes = nil, nil, nil
+	if !reflect.DeepEqual(rules, defaultFilterRules) {
+		t.Errorf("default.json is %+v, built-in is %+v", rules, defaultFilterRules)
+	}
+}
+
+func TestRuleFilter(t *testing.T) {
+	f, err := NewRuleFilter(&FilterRules{
+		Regexps:       []string{`^\d+\.avi$`},
+		MinSize:       1 << 20,
+		MaxSize:       1 << 30,
+		BlockedTypes:  []string{Ed2kFileTypePro},
+		BlockedHashes: []string{"0123456789ABCDEF0123456789ABCDEF"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hash := ConvertEd2kHash32([]byte{0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef, 0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef})
+	tests := []struct {
+		file     FilterFile
+		wantRule string
+	}{
+		{FilterFile{Name: "movie.mkv", Size: 1 << 25, Type: Ed2kFileTypeVideo}, ""},
+		{FilterFile{Name: "movie.mkv", Size: 1 << 25, Type: Ed2kFileTypeVideo, Hash: hash[:]}, "hash"},
+		{FilterFile{Name: "setup.exe", Size: 1 << 25, Type: Ed2kFileTypePro}, "type"},
+		{FilterFile{Name: "movie.mkv", Size: 1 << 10, Type: Ed2kFileTypeVideo}, "size"},
+		{FilterFile{Name: "movie.mkv", Size: 1 << 31, Type: Ed2kFileTypeVideo}, "size"},
+		{FilterFile{Name: "12345.avi", Size: 1 << 25, Type: Ed2kFileTypeVideo}, "regexp"},
+	}
+
+	for _, test := range tests {
+		rule := ""
+		if reason := f.Check(&test.file); reason != nil {
+			rule = reason.Rule
+		}
+		if rule != test.wantRule {
+			t.Errorf("Check(%+v) is %q, want %q", test.file, rule, test.wantRule)
+		}
+	}
+}
@@ -0,0 +1,258 @@
+package com
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const maxEpisode = 200 // bigger number is like resolution or year
+
+// episode token kinds
+const (
+	episodeTokenDigit   = iota // like 05
+	episodeTokenChDigit        // like 二十
+	episodeTokenLetter         // like ep, v
+	episodeTokenOther          // one char, like 第, 集, [, -, space
+)
+
+type episodeToken struct {
+	kind  int
+	s     string
+	value int // for digit kinds
+}
+
+// markers of complete season packs
+var episodePackMarkers = []string{"合集", "全集", "打包", "complete"}
+
+// episodeInfo is season and episode parsed from tokens, -1 if unknown.
+type episodeInfo struct {
+	season     int
+	episode    int
+	episodeEnd int
+	pack       bool
+}
+
+func isChDigit(c rune) bool {
+	_, ok := theChDigits[string(c)]
+	return ok || c == '十'
+}
+
+func getEpisodeTokenKind(c rune) int {
+	switch {
+	case c >= '0' && c <= '9':
+		return episodeTokenDigit
+	case isChDigit(c):
+		return episodeTokenChDigit
+	case c < unicode.MaxASCII && unicode.IsLetter(c):
+		return episodeTokenLetter
+	}
+
+	return episodeTokenOther
+}
+
+// @name: lower case
+func getEpisodeTokens(name string) []*episodeToken {
+	var tokens []*episodeToken
+	for _, c := range name {
+		kind := getEpisodeTokenKind(c)
+		if kind != episodeTokenOther && len(tokens) > 0 && tokens[len(tokens)-1].kind == kind {
+			tokens[len(tokens)-1].s += string(c)
+			continue
+		}
+
+		tokens = append(tokens, &episodeToken{kind: kind, s: string(c)})
+	}
+
+	for _, t := range tokens {
+		if t.kind == episodeTokenDigit || t.kind == episodeTokenChDigit {
+			t.value, _ = parseDigit(t.s, t.kind == episodeTokenChDigit)
+		}
+	}
+
+	return tokens
+}
+
+func (t *episodeToken) isNumber() bool {
+	return t.kind == episodeTokenDigit || t.kind == episodeTokenChDigit
+}
+
+func (t *episodeToken) isEpisode() bool {
+	return t.isNumber() && t.value >= 0 && t.value < maxEpisode && (t.kind == episodeTokenChDigit || len(t.s) <= 3)
+}
+
+// like 05v2
+func (t *episodeToken) isVersion(next *episodeToken) bool {
+	return t.kind == episodeTokenLetter && t.s == "v" && next != nil && next.kind == episodeTokenDigit && len(next.s) == 1
+}
+
+// getEpisodeToken is token at @i, nil if out of range.
+func getEpisodeToken(tokens []*episodeToken, i int) *episodeToken {
+	if i < 0 || i >= len(tokens) {
+		return nil
+	}
+	return tokens[i]
+}
+
+func isEpisodeTokenStr(t *episodeToken, strs ...string) bool {
+	if t == nil {
+		return false
+	}
+
+	for _, s := range strs {
+		if t.s == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isEpisodeBoundary is true if token can be around bare episode number.
+func isEpisodeBoundary(t *episodeToken) bool {
+	return t == nil || isEpisodeTokenStr(t, " ", ".", "_", "[", "]", "【", "】", "(", ")", "-")
+}
+
+// parseEpisodeRange is parsing "N", "N-M" or "N~M" from @i, returning next index.
+// Letters like e/ep before M are skipped, e.g. e01-e10.
+func parseEpisodeRange(tokens []*episodeToken, i int) (int, int, int) {
+	start := tokens[i]
+	i++
+
+	end := -1
+	if isEpisodeTokenStr(getEpisodeToken(tokens, i), "-", "~", "至") {
+		j := i + 1
+		if isEpisodeTokenStr(getEpisodeToken(tokens, j), "e", "ep") {
+			j++
+		}
+		if t := getEpisodeToken(tokens, j); t != nil && t.isEpisode() && t.value > start.value {
+			end = t.value
+			i = j + 1
+		}
+	}
+
+	return start.value, end, i
+}
+
+// parseEpisodeTokens is parsing Chinese and anime style season and episode like
+// 第05集, 第二季第10集, 第01-05集, 全30集, ep05-06, e01-e10 合集, [05], 【05】, 05v2 and " - 05 ".
+// @name: lower case
+func parseEpisodeTokens(name string) *episodeInfo {
+	info := episodeInfo{season: -1, episode: -1, episodeEnd: -1}
+	for _, marker := range episodePackMarkers {
+		if strings.Index(name, marker) != -1 {
+			info.pack = true
+			break
+		}
+	}
+
+	tokens := getEpisodeTokens(name)
+	for i := 0; i < len(tokens); i++ {
+		t := tokens[i]
+		next := getEpisodeToken(tokens, i+1)
+		if next == nil {
+			break
+		}
+
+		switch {
+		case t.s == "第" && next.isNumber():
+			// 第二季, 第10集, 第01-05集
+			value, end, j := parseEpisodeRange(tokens, i+1)
+			unit := getEpisodeToken(tokens, j)
+			if isEpisodeTokenStr(unit, "季", "部") && end == -1 {
+				info.season = value
+				i = j
+			} else if isEpisodeTokenStr(unit, "集", "话", "話", "回") && info.episode == -1 && next.isEpisode() {
+				info.episode, info.episodeEnd = value, end
+				i = j
+			}
+		case t.s == "全" && next.isEpisode() && isEpisodeTokenStr(getEpisodeToken(tokens, i+2), "集", "话", "話"):
+			// 全30集
+			if info.episode == -1 {
+				info.episode, info.episodeEnd = 1, next.value
+			}
+			info.pack = true
+			i += 2
+		case (t.s == "e" || t.s == "ep") && next.kind == episodeTokenDigit && next.isEpisode() &&
+			isEpisodeBoundary(getEpisodeToken(tokens, i-1)):
+			// ep05-06, e01-e10
+			if info.episode == -1 {
+				info.episode, info.episodeEnd, i = parseEpisodeRange(tokens, i+1)
+				i--
+			}
+		case t.kind == episodeTokenDigit && t.isEpisode() && info.episode == -1:
+			prev := getEpisodeToken(tokens, i-1)
+			switch {
+			case isEpisodeTokenStr(prev, "[", "【") && isEpisodeTokenStr(next, "]", "】"):
+				// [05]
+				info.episode = t.value
+			case isEpisodeTokenStr(prev, "[", "【") && next.isVersion(getEpisodeToken(tokens, i+2)) &&
+				isEpisodeTokenStr(getEpisodeToken(tokens, i+3), "]", "】"):
+				// [05v2]
+				info.episode = t.value
+			case isEpisodeBoundary(prev) && next.isVersion(getEpisodeToken(tokens, i+2)) &&
+				isEpisodeBoundary(getEpisodeToken(tokens, i+3)):
+				// 05v2
+				info.episode = t.value
+			case isEpisodeTokenStr(prev, " ") && isEpisodeTokenStr(getEpisodeToken(tokens, i-2), "-") &&
+				isEpisodeTokenStr(next, " ", "."):
+				// Title - 05 [1080p]
+				info.episode = t.value
+			}
+		}
+	}
+
+	// only 1 episode
+	if info.episodeEnd <= info.episode {
+		info.episodeEnd = -1
+	}
+
+	return &info
+}
+
+// multi-episode range is like e01-e03, e01e02, e01-03 or ep01~03
+var episodeRangeRegexp = regexp.MustCompile(`ep?0*(\d{1,3})(?:\s*[-~]\s*(?:ep?)?|ep?)0*(\d{1,3})(?:\D|$)`)
+
+// getEpisodeEnd is getting the last episode of range starting at @episode, -1 if single.
+// @lowerName: lower case
+func getEpisodeEnd(lowerName string, episode int) int {
+	if episode == -1 {
+		return -1
+	}
+
+	for _, match := range episodeRangeRegexp.FindAllStringSubmatch(lowerName, -1) {
+		start, _ := strconv.Atoi(match[1])
+		end, _ := strconv.Atoi(match[2])
+		if start == episode && end > start && end < maxEpisode {
+			return end
+		}
+	}
+
+	return -1
+}
+
+// getAnySeasonEpisode is season and episode of western style like s01e01 or Chinese and anime style like 第二季第10集.
+// Season is 1 if only episode is found and @bDefaultSeason.
+func getAnySeasonEpisode(name string, bDefaultSeason bool) (int, int) {
+	info := parseEpisodeTokens(name)
+
+	// explicit season or range like e01-e10, which is misread as s01e10 by getSeasonEpisode
+	if info.episode != -1 && (info.season != -1 || info.episodeEnd != -1) {
+		if info.season == -1 && bDefaultSeason {
+			return 1, info.episode
+		}
+		return info.season, info.episode
+	}
+
+	season, episode := getSeasonEpisode(name)
+	if season != -1 && episode != -1 {
+		return season, episode
+	}
+
+	if info.episode != -1 && bDefaultSeason {
+		return 1, info.episode
+	}
+
+	return info.season, info.episode
+}
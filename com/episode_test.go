@@ -0,0 +1,85 @@
+package com
+
+import "testing"
+
+func TestParseEpisodeTokens(t *testing.T) {
+	tests := []struct {
+		name                        string
+		season, episode, episodeEnd int
+		pack                        bool
+	}{
+		{"琅琊榜第05集.rmvb", -1, 5, -1, false},
+		{"琅琊榜第二季第10集.mp4", 2, 10, -1, false},
+		{"琅琊榜第01-05集.mkv", -1, 1, 5, false},
+		{"琅琊榜 全30集 合集", -1, 1, 30, true},
+		{"琅琊榜第十二集", -1, 12, -1, false},
+		{"the.walking.dead.ep05-06.mkv", -1, 5, 6, false},
+		{"the.walking.dead.e01-e10 complete", -1, 1, 10, true},
+		{"[sumisora][one piece][05][1080p].mp4", -1, 5, -1, false},
+		{"【悠哈璃羽字幕社】【05v2】", -1, 5, -1, false},
+		{"[horriblesubs] one punch man - 12 [720p].mkv", -1, 12, -1, false},
+		{"one punch man 05v2.mkv", -1, 5, -1, false},
+
+		// not episodes
+		{"inception.2010.1080p.bluray.mkv", -1, -1, -1, false},
+		{"[1080p]", -1, -1, -1, false},
+		{"捉妖记2", -1, -1, -1, false},
+	}
+
+	for _, test := range tests {
+		info := parseEpisodeTokens(test.name)
+		if info.season != test.season || info.episode != test.episode || info.episodeEnd != test.episodeEnd ||
+			info.pack != test.pack {
+			t.Errorf("parseEpisodeTokens(%s) = %+v, want %d %d %d %v", test.name, *info,
+				test.season, test.episode, test.episodeEnd, test.pack)
+		}
+	}
+}
+
+func TestGetEpisodeEnd(t *testing.T) {
+	tests := []struct {
+		name             string
+		episode, wantEnd int
+	}{
+		{"the.walking.dead.s01e01-e03.mkv", 1, 3},
+		{"the.walking.dead.s01e01e02.mkv", 1, 2},
+		{"the.walking.dead.s01e01-03.mkv", 1, 3},
+		{"the.walking.dead.s01ep01~03.mkv", 1, 3},
+		{"the.walking.dead.s01e01 - e03.mkv", 1, 3},
+		{"the.walking.dead.s01e05.mkv", 5, -1},
+		{"the.walking.dead.s01e05.720p.mkv", 5, -1},
+		{"the.walking.dead.s01e03-e01.mkv", 3, -1},
+		{"the.walking.dead.s01e01.mkv", -1, -1},
+	}
+
+	for _, test := range tests {
+		if end := getEpisodeEnd(test.name, test.episode); end != test.wantEnd {
+			t.Errorf("getEpisodeEnd(%s, %d) = %d, want %d", test.name, test.episode, end, test.wantEnd)
+		}
+	}
+}
+
+func TestGetAnySeasonEpisode(t *testing.T) {
+	tests := []struct {
+		name            string
+		bDefaultSeason  bool
+		season, episode int
+	}{
+		{"the.walking.dead.s02e05.720p.mkv", false, 2, 5},
+		{"琅琊榜第二季第10集.mp4", false, 2, 10},
+		{"琅琊榜第05集.rmvb", false, -1, 5},
+		{"琅琊榜第05集.rmvb", true, 1, 5},
+
+		// not s01e10
+		{"the.walking.dead.e01-e10.mkv", true, 1, 1},
+		{"inception.2010.1080p.mkv", true, -1, -1},
+	}
+
+	for _, test := range tests {
+		season, episode := getAnySeasonEpisode(test.name, test.bDefaultSeason)
+		if season != test.season || episode != test.episode {
+			t.Errorf("getAnySeasonEpisode(%s, %v) = %d, %d, want %d, %d", test.name, test.bDefaultSeason,
+				season, episode, test.season, test.episode)
+		}
+	}
+}
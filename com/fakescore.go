@@ -0,0 +1,167 @@
+package com
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// FakeScoreThreshold is score from which file is likely fake.
+const FakeScoreThreshold = 50
+
+const (
+	fakeScoreMax = 100
+
+	fakeMinVideoSize       = 20 * 1024 * 1024 // byte, smaller video is nearly always fake or sample
+	fakeMinVideoBitrate    = 100              // kbps
+	fakeMaxVideoBitrate    = 100 * 1024       // kbps
+	fakeMaxNameKeywordNbr  = 25               // more keywords are stuffing
+	fakeMaxNameYearNbr     = 3                // more different years are stuffing
+	fakeMinUnrelatedNames  = 2                // same hash under more unrelated names is suspicious
+	fakeUnrelatedNameScore = 15               // for each more unrelated name
+)
+
+// FakeCheckFile is attributes of file for fake scoring.
+type FakeCheckFile struct {
+	Name        string
+	Size        uint64
+	Type        string // ED2K file type from publisher
+	MediaLength uint32 // second, 0 if unknown
+
+	OtherNames []string // other names of same hash from other publishers
+}
+
+// program wrappers named like videos
+var fakeProgramExts = map[string]bool{".exe": true, ".scr": true, ".bat": true, ".com": true, ".pif": true, ".vbs": true, ".msi": true, ".apk": true}
+
+// WMV/ASF are often DRM wrappers asking to "download codec" from somewhere
+var fakeWrapperExts = map[string]bool{".wmv": true, ".asf": true}
+
+// name patterns of known spam publishers, e.g. advertisement of sites and chat groups
+var fakeSpamPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)www\.[a-z0-9-]+\.(com|net|org|cn|cc|tv)`),
+	regexp.MustCompile(`(?i)(qq群|加群|微信号|vx[:：])`),
+	regexp.MustCompile(`(?i)(password|keygen|codec[ _.-]*(pack|download)|free[ _.-]*download)`),
+	regexp.MustCompile(`(?i)\.(avi|mkv|mp4|rmvb|wmv)\.(exe|scr|bat|com|zip|rar)$`),
+}
+
+var fakeYearRegexp = regexp.MustCompile(`(19|20)\d\d`)
+
+type fakeScorer struct {
+	score   int
+	reasons []string
+}
+
+func (s *fakeScorer) add(score int, format string, args ...interface{}) {
+	s.score += score
+	s.reasons = append(s.reasons, fmt.Sprintf(format, args...))
+}
+
+// GetFakeScore is scoring how likely file is fake or spam, from 0 to 100, with reasons.
+func GetFakeScore(file *FakeCheckFile) (int, []string) {
+	s := fakeScorer{}
+
+	lowerName := strings.ToLower(file.Name)
+	ext := path.Ext(lowerName)
+	extType := GetEd2kFileTypeByExt(lowerName)
+
+	// extension vs type
+	if fakeProgramExts[ext] {
+		s.add(60, "program extension %s", ext)
+	} else if extType != "" && file.Type != "" && extType != file.Type {
+		s.add(30, "extension %s is not %s", ext, file.Type)
+	}
+	if fakeWrapperExts[ext] {
+		s.add(20, "wrapper extension %s", ext)
+	}
+
+	// size vs media length
+	if file.Type == Ed2kFileTypeVideo || extType == Ed2kFileTypeVideo {
+		if file.Size < fakeMinVideoSize {
+			s.add(40, "video too small: %d bytes", file.Size)
+		}
+
+		if file.MediaLength > 0 {
+			bitrate := file.Size * 8 / 1024 / uint64(file.MediaLength)
+			if bitrate < fakeMinVideoBitrate {
+				s.add(40, "bitrate too low: %d kbps", bitrate)
+			} else if bitrate > fakeMaxVideoBitrate {
+				s.add(30, "bitrate too high: %d kbps", bitrate)
+			}
+		}
+	}
+
+	// keyword stuffing
+	keywords := Split2Keywords(lowerName)
+	if len(keywords) > fakeMaxNameKeywordNbr {
+		s.add(20, "too many keywords: %d", len(keywords))
+	}
+	years := make(map[string]bool)
+	for _, year := range fakeYearRegexp.FindAllString(lowerName, -1) {
+		years[year] = true
+	}
+	if len(years) > fakeMaxNameYearNbr {
+		s.add(20, "too many years: %d", len(years))
+	}
+
+	// spam publishers
+	for _, re := range fakeSpamPatterns {
+		if re.MatchString(file.Name) {
+			s.add(30, "spam pattern %s", re.String())
+			break
+		}
+	}
+
+	// identical hash under many unrelated names
+	if n := CountUnrelatedNames(file.Name, file.OtherNames); n > fakeMinUnrelatedNames {
+		s.add(fakeUnrelatedNameScore*(n-fakeMinUnrelatedNames), "%d unrelated names", n)
+	}
+
+	if s.score > fakeScoreMax {
+		s.score = fakeScoreMax
+	}
+
+	return s.score, s.reasons
+}
+
+// CountUnrelatedNames is counting names which have no common primary keyword with @name.
+// Extension and release information like mkv, S01E01 or 720p are not compared, they're in nearly all names.
+func CountUnrelatedNames(name string, otherNames []string) int {
+	keywordMap := make(map[string]bool)
+	for _, keyword := range getFakeNameKeywords(name) {
+		keywordMap[keyword] = true
+	}
+
+	n := 0
+	for _, otherName := range otherNames {
+		related := false
+		for _, keyword := range getFakeNameKeywords(otherName) {
+			if keywordMap[keyword] {
+				related = true
+				break
+			}
+		}
+
+		if !related {
+			n++
+		}
+	}
+
+	return n
+}
+
+func getFakeNameKeywords(name string) []string {
+	name = strings.ToLower(name)
+	name = strings.TrimSuffix(name, path.Ext(name))
+	keywords, _ := GetPrimaryKeywords(name)
+
+	var newKeywords []string
+	for _, keyword := range keywords {
+		if !isTitleReleaseToken(keyword) {
+			newKeywords = append(newKeywords, keyword)
+		}
+	}
+
+	return newKeywords
+}
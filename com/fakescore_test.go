@@ -0,0 +1,99 @@
+package com
+
+import (
+	"strings"
+	"testing"
+)
+
+const fakeTestMB = 1024 * 1024
+
+func TestGetFakeScore(t *testing.T) {
+	tests := []struct {
+		file       FakeCheckFile
+		wantScore  int
+		wantReason string // prefix of one reason, empty if none
+	}{
+		{FakeCheckFile{Name: "The.Walking.Dead.S01E01.720p.mkv", Size: 700 * fakeTestMB, Type: Ed2kFileTypeVideo, MediaLength: 2700}, 0, ""},
+		{FakeCheckFile{Name: "琅琊榜第01集.rmvb", Size: 300 * fakeTestMB, Type: Ed2kFileTypeVideo}, 0, ""},
+
+		// extension vs type
+		{FakeCheckFile{Name: "Inception.2010.exe", Size: 700 * fakeTestMB, Type: Ed2kFileTypePro}, 60, "program extension"},
+		{FakeCheckFile{Name: "Inception.2010.mkv", Size: 700 * fakeTestMB, Type: Ed2kFileTypeAudio}, 30, "extension .mkv is not"},
+		{FakeCheckFile{Name: "Inception.2010.wmv", Size: 700 * fakeTestMB, Type: Ed2kFileTypeVideo}, 20, "wrapper extension"},
+
+		// size vs media length
+		{FakeCheckFile{Name: "Inception.2010.mkv", Size: 5 * fakeTestMB, Type: Ed2kFileTypeVideo}, 40, "video too small"},
+		{FakeCheckFile{Name: "Inception.2010.mkv", Size: 50 * fakeTestMB, Type: Ed2kFileTypeVideo, MediaLength: 7200}, 40, "bitrate too low"},
+		{FakeCheckFile{Name: "Inception.2010.mkv", Size: 4096 * fakeTestMB, Type: Ed2kFileTypeVideo, MediaLength: 60}, 30, "bitrate too high"},
+
+		// stuffing and spam
+		{FakeCheckFile{Name: "a1 b2 c3 d4 e5 f6 g7 h8 i9 j10 k11 l12 m13 n14 o15 p16 q17 r18 s19 t20 u21 v22 w23 x24 y25 z26.mkv", Size: 700 * fakeTestMB, Type: Ed2kFileTypeVideo}, 20, "too many keywords"},
+		{FakeCheckFile{Name: "Movies.1999.2001.2005.2010.mkv", Size: 700 * fakeTestMB, Type: Ed2kFileTypeVideo}, 20, "too many years"},
+		{FakeCheckFile{Name: "www.abcmovie.com.Inception.2010.mkv", Size: 700 * fakeTestMB, Type: Ed2kFileTypeVideo}, 30, "spam pattern"},
+
+		// capped
+		{FakeCheckFile{Name: "Inception.avi.exe", Size: fakeTestMB, Type: Ed2kFileTypeVideo}, fakeScoreMax, "program extension"},
+	}
+
+	for _, test := range tests {
+		score, reasons := GetFakeScore(&test.file)
+		if score != test.wantScore {
+			t.Errorf("GetFakeScore(%q) = %d, want %d, reasons %v", test.file.Name, score, test.wantScore, reasons)
+		}
+
+		if test.wantReason == "" {
+			if len(reasons) != 0 {
+				t.Errorf("GetFakeScore(%q) reasons = %v, want none", test.file.Name, reasons)
+			}
+			continue
+		}
+
+		found := false
+		for _, reason := range reasons {
+			if strings.HasPrefix(reason, test.wantReason) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("GetFakeScore(%q) reasons = %v, want %q", test.file.Name, reasons, test.wantReason)
+		}
+	}
+}
+
+func TestGetFakeScoreOtherNames(t *testing.T) {
+	file := FakeCheckFile{Name: "The.Walking.Dead.S01E01.720p.mkv", Size: 700 * fakeTestMB, Type: Ed2kFileTypeVideo}
+
+	// score grows as unrelated names of same hash come, translated name is unrelated too
+	otherNames := []string{"行尸走肉.第一季.第01集.mkv", "Walking.Dead.S01E01.mkv", "Inception.2010.mkv", "Avatar.2009.mkv"}
+	wantScores := []int{0, 0, 0, fakeUnrelatedNameScore}
+	for i := range otherNames {
+		file.OtherNames = otherNames[:i+1]
+		if score, _ := GetFakeScore(&file); score != wantScores[i] {
+			t.Errorf("GetFakeScore with %v = %d, want %d", file.OtherNames, score, wantScores[i])
+		}
+	}
+
+	file.OtherNames = append(otherNames, "Titanic.1997.mkv", "Interstellar.2014.mkv")
+	if score, reasons := GetFakeScore(&file); score != fakeUnrelatedNameScore*3 {
+		t.Errorf("GetFakeScore with %v = %d %v, want %d", file.OtherNames, score, reasons, fakeUnrelatedNameScore*3)
+	}
+}
+
+func TestCountUnrelatedNames(t *testing.T) {
+	tests := []struct {
+		name       string
+		otherNames []string
+		want       int
+	}{
+		{"The.Walking.Dead.S01E01.mkv", nil, 0},
+		{"The.Walking.Dead.S01E01.mkv", []string{"walking dead 01.avi", "The Walking Dead S01E01 720p.mkv"}, 0},
+		{"The.Walking.Dead.S01E01.mkv", []string{"Inception.2010.mkv", "The.Dead.Zone.mkv"}, 1},
+		{"捉妖记2.rmvb", []string{"捉妖记2.国语.rmvb", "Monster.Hunt.2.mkv"}, 1},
+	}
+
+	for _, test := range tests {
+		if got := CountUnrelatedNames(test.name, test.otherNames); got != test.want {
+			t.Errorf("CountUnrelatedNames(%q, %v) = %d, want %d", test.name, test.otherNames, got, test.want)
+		}
+	}
+}
@@ -5,7 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
-	"regexp"
+	"net/url"
+	"strconv"
 	"strings"
 )
 
@@ -20,6 +21,11 @@ type FileInfo struct {
 	ChName  string
 	Season  int
 	Episode int
+	Year    int // release year of item, or from file name if unknown, 0 if both unknown
+
+	EpisodeEnd int         // last episode of multi-episode range like S01E01-E03 or 第01-05集, -1 if single
+	IsPack     bool        // complete season pack like 全30集 or 合集
+	Release    ReleaseInfo // quality information from file name
 }
 
 // Ed2kFileLink x
@@ -27,10 +33,16 @@ type Ed2kFileLink struct {
 	FileInfo
 
 	// attributes from ED2K
-	Name  string
-	Size  uint64
-	Avail uint32
-	Hash  []byte
+	Name     string
+	Size     uint64
+	Avail    uint32
+	Hash     []byte
+	AICHHash []byte // nil if unknown
+
+	KeywordHits int // number of keyword searches returned this file, 0 if not multi-keyword search
+
+	FakeScore   int      // 0 - 100, likely fake from FakeScoreThreshold
+	FakeReasons []string // why it's suspicious
 }
 
 type ed2kFileLinkJSON struct {
@@ -41,16 +53,19 @@ type ed2kFileLinkJSON struct {
 	Size  uint64
 	Avail uint32
 	Link  string
+	Links map[string]string `json:",omitempty"` // other link formats, see LinkFormat
+
+	KeywordHits int `json:",omitempty"`
+
+	FakeScore   int
+	FakeReasons []string `json:",omitempty"`
 }
 
 // @name: lower case
 // @orgName: lower case
 func parseName(name string, orgName string) bool {
 	// check if containing orginal name
-	keywords := Split2Keywords(orgName)
-	pattern := strings.Join(keywords, ".*")
-	match, _ := regexp.MatchString(pattern, name)
-	return match
+	return IsTitleMatched(name, orgName)
 }
 
 // @name: lower case
@@ -61,7 +76,8 @@ func parseSeasonTVName(name string, orgName string) (int, int) {
 		return -1, -1
 	}
 
-	return getSeasonEpisode(name)
+	// like 第10集 of the first season
+	return getAnySeasonEpisode(name, true)
 }
 
 // @name: lower case
@@ -72,6 +88,11 @@ func parseNoSeasonTVName(name string, orgName string) int {
 		return -1
 	}
 
+	// Chinese and anime style first, which is more accurate
+	if info := parseEpisodeTokens(name); info.episode != -1 {
+		return info.episode
+	}
+
 	return getEpisode(name)
 }
 
@@ -83,10 +104,13 @@ func parseUnknownTypeName(name string, orgName string) (int, int, byte) {
 		return -1, -1, UnknownType
 	}
 
-	season, episode := getSeasonEpisode(name)
+	season, episode := getAnySeasonEpisode(name, false)
 	if season != -1 && episode != -1 {
 		return season, episode, SeasonTV
 	}
+	if episode != -1 {
+		return -1, episode, NoSeasonTV
+	}
 
 	episode = getEpisode(name)
 	if episode != -1 {
@@ -223,13 +247,15 @@ func getSeasonEpisode(name string) (int, int) {
 func ToFileInfo(name string, items []*Item) *FileInfo {
 	// match
 	lowerName := strings.ToLower(name)
+	fileYear := GetYear(lowerName)
 	var fileInfo *FileInfo
-	for _, item := range items {
+	for _, item := range sortItemsByYear(items, fileYear) {
 		if fileInfo != nil {
 			break
 		}
 
 		orgName := strings.ToLower(item.OrgName)
+		titleYear := getYearWithoutTitle(lowerName, orgName) // title might be year like 1917
 
 		switch item.Type {
 		case SeasonTV:
@@ -248,26 +274,41 @@ func ToFileInfo(name string, items []*Item) *FileInfo {
 			}
 		case Movie:
 			match := parseName(lowerName, orgName)
-			if match {
+			if match && isYearMatched(item.Year, titleYear) { // not remake or another movie of same name
 				fileInfo = &FileInfo{Type: item.Type, OrgName: item.OrgName, ChName: item.ChName,
 					Season:  -1,
 					Episode: -1}
 			}
 		default:
 			season, episode, itemType := parseUnknownTypeName(lowerName, orgName)
-			if itemType != UnknownType {
+			if itemType != UnknownType && (itemType != Movie || isYearMatched(item.Year, titleYear)) {
 				// Note that we don't set type of item because item type cannot be inferred by movie/tv name.
 				fileInfo = &FileInfo{Type: itemType, OrgName: item.OrgName, ChName: item.ChName,
 					Season:  season,
 					Episode: episode}
 			}
 		}
+
+		if fileInfo != nil {
+			fileInfo.Year = item.Year
+		}
 	}
 
 	if fileInfo == nil {
 		return nil
 	}
 
+	episodeInfo := parseEpisodeTokens(lowerName)
+	fileInfo.EpisodeEnd = getEpisodeEnd(lowerName, fileInfo.Episode)
+	if fileInfo.EpisodeEnd == -1 && episodeInfo.episode == fileInfo.Episode {
+		fileInfo.EpisodeEnd = episodeInfo.episodeEnd
+	}
+	fileInfo.IsPack = episodeInfo.pack
+	fileInfo.Release = *ParseReleaseName(name)
+	if fileInfo.Year == 0 {
+		fileInfo.Year = getYearWithoutTitle(lowerName, strings.ToLower(fileInfo.OrgName))
+	}
+
 	// accurate check for yellow check
 	if fileInfo.OrgName != fileInfo.ChName {
 		// Not Chinese movie or TV
@@ -282,8 +323,8 @@ func ToFileInfo(name string, items []*Item) *FileInfo {
 		}
 
 		if hasCh {
-			chName := strings.ToLower(fileInfo.ChName)
-			if strings.Index(lowerName, chName) == -1 {
+			chName := NormalizeChinese(fileInfo.ChName)
+			if strings.Index(NormalizeChinese(lowerName), chName) == -1 {
 				return nil
 			}
 		}
@@ -294,7 +335,7 @@ func ToFileInfo(name string, items []*Item) *FileInfo {
 
 // GetEd2kLink x
 func (f *Ed2kFileLink) GetEd2kLink() string {
-	return GetEd2kLink(f.Name, f.Size, f.Hash)
+	return GetEd2kLinkWithAICH(f.Name, f.Size, f.Hash, f.AICHHash)
 }
 
 // GetHash x
@@ -318,7 +359,12 @@ func (f *Ed2kFileLink) ToJSON() []byte {
 		Name:     f.Name,
 		Size:     f.Size,
 		Avail:    f.Avail,
-		Link:     f.GetEd2kLink()}
+		Link:     f.GetEd2kLink(),
+		Links:    f.GetLinks(),
+
+		KeywordHits: f.KeywordHits,
+		FakeScore:   f.FakeScore,
+		FakeReasons: f.FakeReasons}
 
 	b, _ := json.Marshal(linkJSON)
 	return b
@@ -333,6 +379,63 @@ func GetEd2kLink(name string, size uint64, hash []byte) string {
 		encodeBase16(newHash[:]))
 }
 
+// GetEd2kLinkWithAICH is getting ED2K link with AICH hash like ed2k://|file|name|size|hash|h=aich|/.
+// If AICH hash is unknown, it's same as GetEd2kLink.
+func GetEd2kLinkWithAICH(name string, size uint64, hash []byte, aichHash []byte) string {
+	link := GetEd2kLink(name, size, hash)
+
+	aich := GetAICHHashStr(aichHash)
+	if aich == "" {
+		return link
+	}
+
+	return link[:len(link)-1] + "h=" + aich + "|/"
+}
+
+// ParseEd2kLink is parsing ED2K link like ed2k://|file|name|size|hash|/ to file link.
+// AICH hash part like h=aich| is optional.
+// Returned hash is in KAD order same as file link from KAD network.
+func ParseEd2kLink(link string) *Ed2kFileLink {
+	if !strings.HasPrefix(strings.ToLower(link), "ed2k://|file|") {
+		return nil
+	}
+
+	parts := strings.Split(link[len("ed2k://|file|"):], "|")
+	if len(parts) < 3 {
+		return nil
+	}
+
+	name, err := url.PathUnescape(parts[0])
+	if err != nil {
+		name = parts[0]
+	}
+	if name == "" {
+		return nil
+	}
+
+	size, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return nil
+	}
+
+	hash, err := hex.DecodeString(parts[2])
+	if err != nil || len(hash) != 16 {
+		return nil
+	}
+	kadHash := ConvertEd2kHash32(hash)
+
+	// optional AICH hash
+	var aichHash []byte
+	for _, part := range parts[3:] {
+		if strings.HasPrefix(part, "h=") {
+			aichHash = ParseAICHHashStr(part[len("h="):])
+		}
+	}
+
+	fileInfo := FileInfo{Type: UnknownType, Season: -1, Episode: -1, EpisodeEnd: -1}
+	return &Ed2kFileLink{FileInfo: fileInfo, Name: name, Size: size, Hash: kadHash[:], AICHHash: aichHash}
+}
+
 // ConvertEd2kHash32 x
 func ConvertEd2kHash32(srcHash []byte) [16]byte {
 	// change to inverse endian for each uint32
@@ -0,0 +1,52 @@
+package com
+
+import (
+	"path"
+	"strings"
+)
+
+// ED2K file types published in KAD, same as eMule
+const (
+	Ed2kFileTypeAudio      = "Audio"
+	Ed2kFileTypeVideo      = "Video"
+	Ed2kFileTypeImage      = "Image"
+	Ed2kFileTypeDoc        = "Doc"
+	Ed2kFileTypePro        = "Pro" // program
+	Ed2kFileTypeArc        = "Arc" // archive
+	Ed2kFileTypeIso        = "Iso" // CD image
+	Ed2kFileTypeCollection = "EmuleCollection"
+)
+
+// Some publishers don't give file type, it's known by extension like eMule.
+var ed2kFileTypeExts = map[string]string{
+	".mp3": Ed2kFileTypeAudio, ".ape": Ed2kFileTypeAudio, ".flac": Ed2kFileTypeAudio, ".wav": Ed2kFileTypeAudio,
+	".wma": Ed2kFileTypeAudio, ".ogg": Ed2kFileTypeAudio, ".aac": Ed2kFileTypeAudio, ".m4a": Ed2kFileTypeAudio,
+	".ac3": Ed2kFileTypeAudio, ".mpc": Ed2kFileTypeAudio, ".tta": Ed2kFileTypeAudio, ".cue": Ed2kFileTypeAudio,
+
+	".avi": Ed2kFileTypeVideo, ".mkv": Ed2kFileTypeVideo, ".mp4": Ed2kFileTypeVideo, ".rmvb": Ed2kFileTypeVideo,
+	".rm": Ed2kFileTypeVideo, ".wmv": Ed2kFileTypeVideo, ".mpg": Ed2kFileTypeVideo, ".mpeg": Ed2kFileTypeVideo,
+	".mov": Ed2kFileTypeVideo, ".ts": Ed2kFileTypeVideo, ".vob": Ed2kFileTypeVideo, ".flv": Ed2kFileTypeVideo,
+
+	".jpg": Ed2kFileTypeImage, ".jpeg": Ed2kFileTypeImage, ".png": Ed2kFileTypeImage, ".gif": Ed2kFileTypeImage,
+	".bmp": Ed2kFileTypeImage, ".tif": Ed2kFileTypeImage, ".tiff": Ed2kFileTypeImage,
+
+	".pdf": Ed2kFileTypeDoc, ".doc": Ed2kFileTypeDoc, ".docx": Ed2kFileTypeDoc, ".txt": Ed2kFileTypeDoc,
+	".chm": Ed2kFileTypeDoc, ".epub": Ed2kFileTypeDoc, ".mobi": Ed2kFileTypeDoc, ".djvu": Ed2kFileTypeDoc,
+	".xls": Ed2kFileTypeDoc, ".ppt": Ed2kFileTypeDoc, ".rtf": Ed2kFileTypeDoc,
+
+	".exe": Ed2kFileTypePro, ".msi": Ed2kFileTypePro, ".apk": Ed2kFileTypePro, ".dmg": Ed2kFileTypePro,
+	".com": Ed2kFileTypePro, ".bat": Ed2kFileTypePro,
+
+	".zip": Ed2kFileTypeArc, ".rar": Ed2kFileTypeArc, ".7z": Ed2kFileTypeArc, ".tar": Ed2kFileTypeArc,
+	".gz": Ed2kFileTypeArc, ".bz2": Ed2kFileTypeArc, ".ace": Ed2kFileTypeArc, ".cab": Ed2kFileTypeArc,
+
+	".iso": Ed2kFileTypeIso, ".bin": Ed2kFileTypeIso, ".nrg": Ed2kFileTypeIso,
+	".img": Ed2kFileTypeIso, ".mdf": Ed2kFileTypeIso, ".mds": Ed2kFileTypeIso, ".ccd": Ed2kFileTypeIso,
+
+	CollectionExt: Ed2kFileTypeCollection,
+}
+
+// GetEd2kFileTypeByExt is getting ED2K file type by extension of file name, empty if unknown.
+func GetEd2kFileTypeByExt(name string) string {
+	return ed2kFileTypeExts[strings.ToLower(path.Ext(name))]
+}
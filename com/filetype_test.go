@@ -0,0 +1,35 @@
+package com
+
+import (
+	"testing"
+)
+
+func TestGetEd2kFileTypeByExt(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Song.mp3", Ed2kFileTypeAudio},
+		{"Album.FLAC", Ed2kFileTypeAudio},
+		{"Album.cue", Ed2kFileTypeAudio},
+		{"The.Walking.Dead.S01E01.mkv", Ed2kFileTypeVideo},
+		{"琅琊榜第01集.RMVB", Ed2kFileTypeVideo},
+		{"cover.jpg", Ed2kFileTypeImage},
+		{"book.epub", Ed2kFileTypeDoc},
+		{"setup.exe", Ed2kFileTypePro},
+		{"files.7z", Ed2kFileTypeArc},
+		{"ubuntu.iso", Ed2kFileTypeIso},
+		{"The Walking Dead" + CollectionExt, Ed2kFileTypeCollection},
+
+		// unknown
+		{"readme", ""},
+		{"Inception.2010", ""},
+		{"file.xyz", ""},
+	}
+
+	for _, test := range tests {
+		if got := GetEd2kFileTypeByExt(test.name); got != test.want {
+			t.Errorf("GetEd2kFileTypeByExt(%q) = %q, want %q", test.name, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package com
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	keywordFileVersion     = 1
+	keywordManagerSaveTime = 10 // minute
+)
+
+// keywordFile is snapshot of KeywordManager in JSON, entries are from least recently to most recently.
+type keywordFile struct {
+	Version int
+	Entries []*keywordFileEntry
+}
+
+type keywordFileEntry struct {
+	KeyStr   string
+	Items    []*Item
+	TFetched int64 // unix second
+}
+
+// GetKeywordManagerFileName x
+func GetKeywordManagerFileName() string {
+	return GetConfigPath() + "/config/door/keywords.json"
+}
+
+// Save is writing all keywords and items to file, replacing old file only after writing succeeded.
+func (m *KeywordManager) Save(fileName string) error {
+	f := keywordFile{Version: keywordFileVersion}
+
+	m.lock.Lock()
+
+	for e := m.list.Front(); e != nil; e = e.Next() {
+		node := e.Value.(*KeywordListNode)
+		f.Entries = append(f.Entries, &keywordFileEntry{KeyStr: node.keyStr, Items: node.items, TFetched: node.tFetched})
+	}
+	m.bDirty = false
+
+	data, err := json.Marshal(&f)
+
+	m.lock.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(fileName), 0755); err != nil {
+		return err
+	}
+
+	tmpFileName := fileName + ".tmp"
+	if err := ioutil.WriteFile(tmpFileName, data, 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpFileName, fileName)
+}
+
+// Load is reading keywords and items from file saved by Save, existing ones are kept.
+// Key strings are merged by canonical keywords, so entries of same keywords in different order are one.
+func (m *KeywordManager) Load(fileName string) error {
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return err
+	}
+
+	var f keywordFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return err
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	for _, entry := range f.Entries {
+		keywords := Split2Keywords(entry.KeyStr)
+		if len(keywords) == 0 || len(entry.Items) == 0 {
+			continue
+		}
+
+		if node := m.getNode(keywords); node != nil {
+			m.setExisting(node, entry.Items, entry.TFetched)
+		} else {
+			m.setNew(keywords, entry.Items, entry.TFetched)
+		}
+	}
+
+	HhjLog.Infof("Keyword manager loaded %d entries: %s", len(f.Entries), fileName)
+
+	return nil
+}
+
+// StartSaving is saving to file periodically if changed.
+func (m *KeywordManager) StartSaving(fileName string) {
+	go func() {
+		for {
+			time.Sleep(keywordManagerSaveTime * time.Minute)
+			m.saveIfDirty(fileName)
+		}
+	}()
+}
+
+// saveIfDirty is saving to file if changed since last saving, true if saved.
+func (m *KeywordManager) saveIfDirty(fileName string) bool {
+	m.lock.RLock()
+	bDirty := m.bDirty
+	m.lock.RUnlock()
+
+	if !bDirty {
+		return false
+	}
+
+	if err := m.Save(fileName); err != nil {
+		HhjLog.Errorf("Save keyword manager failed: %s", err)
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,124 @@
+package com
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newKeywordFileTestDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "keywordfile")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return dir
+}
+
+func TestKeywordFileRoundTrip(t *testing.T) {
+	dir := newKeywordFileTestDir(t)
+	defer os.RemoveAll(dir)
+	fileName := filepath.Join(dir, "door", "keywords.json")
+
+	m := NewKeywordManager()
+	m.Set([]string{"walking", "dead"}, []*Item{{Type: SeasonTV, OrgName: "The Walking Dead", ChName: "行尸走肉", Year: 2010}})
+	m.Set([]string{"琅琊榜"}, []*Item{{Type: NoSeasonTV, OrgName: "琅琊榜", ChName: "琅琊榜", Year: 2015}})
+	m.getNode([]string{"walking", "dead"}).tFetched = 1000 // fetched long ago
+
+	// saved only if changed
+	if !m.saveIfDirty(fileName) {
+		t.Fatal("not saved")
+	}
+	if m.saveIfDirty(fileName) {
+		t.Error("saved again without change")
+	}
+	if _, err := os.Stat(fileName + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("temp file is left: %v", err)
+	}
+
+	n := NewKeywordManager()
+	if err := n.Load(fileName); err != nil {
+		t.Fatal(err)
+	}
+
+	node := n.getNode([]string{"dead", "walking"})
+	if node == nil || node.keyStr != "walking dead" || node.tFetched != 1000 || len(node.items) != 1 || node.items[0].ChName != "行尸走肉" {
+		t.Fatalf("walking dead: %+v", node)
+	}
+	if items := n.Get([]string{"lyb"}); len(items) != 1 || items[0].Year != 2015 {
+		t.Errorf("琅琊榜 by pinyin: %v", items)
+	}
+
+	// order of least recently is kept
+	if keyStrs := n.GetKeyStrs(); len(keyStrs) != 2 || keyStrs[0] != "walking dead" {
+		t.Errorf("key strings: %q", keyStrs)
+	}
+}
+
+func TestKeywordFileMerge(t *testing.T) {
+	dir := newKeywordFileTestDir(t)
+	defer os.RemoveAll(dir)
+	fileName := filepath.Join(dir, "keywords.json")
+
+	// same keywords in different order, saved by old version
+	f := keywordFile{Version: keywordFileVersion, Entries: []*keywordFileEntry{
+		{KeyStr: "walking dead", Items: []*Item{{Type: SeasonTV, OrgName: "The Walking Dead"}}, TFetched: 1000},
+		{KeyStr: "dead walking", Items: []*Item{{Type: SeasonTV, OrgName: "Fear the Walking Dead"}}, TFetched: 2000},
+		{KeyStr: "", Items: []*Item{{OrgName: "Nothing"}}},
+		{KeyStr: "empty"},
+	}}
+	data, _ := json.Marshal(&f)
+	if err := ioutil.WriteFile(fileName, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewKeywordManager()
+	if err := m.Load(fileName); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(m.nodeMap) != 1 {
+		t.Fatalf("%d entries, expected 1", len(m.nodeMap))
+	}
+	node := m.getNode([]string{"walking", "dead"})
+	if len(node.items) != 2 || node.tFetched != 2000 {
+		t.Errorf("merged: %d items, fetched at %d", len(node.items), node.tFetched)
+	}
+
+	// bad file
+	ioutil.WriteFile(fileName, []byte("{"), 0644)
+	if err := m.Load(fileName); err == nil {
+		t.Error("bad file is loaded")
+	}
+}
+
+func TestKeywordFileEviction(t *testing.T) {
+	dir := newKeywordFileTestDir(t)
+	defer os.RemoveAll(dir)
+	fileName := filepath.Join(dir, "keywords.json")
+
+	f := keywordFile{Version: keywordFileVersion}
+	for i := 0; i < maxKeywordsNbr+10; i++ {
+		f.Entries = append(f.Entries, &keywordFileEntry{KeyStr: fmt.Sprintf("title n%d", i), Items: []*Item{{OrgName: "Title"}}, TFetched: int64(i)})
+	}
+	data, _ := json.Marshal(&f)
+	if err := ioutil.WriteFile(fileName, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewKeywordManager()
+	if err := m.Load(fileName); err != nil {
+		t.Fatal(err)
+	}
+
+	// the least recently ones in file are removed
+	if len(m.nodeMap) != maxKeywordsNbr || m.list.Len() != maxKeywordsNbr {
+		t.Fatalf("%d entries in map and %d in list, expected %d", len(m.nodeMap), m.list.Len(), maxKeywordsNbr)
+	}
+	if m.Get([]string{"title", "n9"}) != nil || m.Get([]string{"title", "n10"}) == nil {
+		t.Error("wrong entries are removed")
+	}
+}
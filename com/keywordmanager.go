@@ -2,97 +2,139 @@ package com
 
 import (
 	"container/list"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 )
 
-const maxKeywordsNbr = 10 * 1000
+const (
+	maxKeywordsNbr  = 10 * 1000
+	maxRelatedNodes = 5 // most related entries for GetRelated
+)
 
 // KeywordListNode is list node of items mappting to keywords.
 type KeywordListNode struct {
 	e *list.Element
 
-	keyStr string
-	items  []*Item
+	keyStr     string   // keywords in order of user input, for searching again
+	keys       []string // canonical keywords, see getCanonicalKeys
+	pinyinKeys []string // pinyin and its initials of Chinese keywords, for user input in pinyin
+	items      []*Item
+	tFetched   int64 // when items were fetched from Internet
 }
 
 // KeywordManager is a manager for user search primary keywords mapping to items from DouBan.
 /////////////////////////////////////////////////////////////////////////////////////////////
 type KeywordManager struct {
 	list    *list.List
-	nodeMap map[string]*KeywordListNode // key is string of keywords.
+	nodeMap map[string]*KeywordListNode          // key is canonical string of keywords.
+	wordMap map[string]map[*KeywordListNode]bool // key is one of canonical keywords, for subset and superset lookup.
+
+	pinyinMap map[string]*KeywordListNode // key is pinyin or its initials of keywords, without space.
+
+	bDirty bool // changed since last saving
 
 	lock sync.RWMutex
 }
 
 // NewKeywordManager x
 func NewKeywordManager() *KeywordManager {
-	m := KeywordManager{list: list.New(), nodeMap: make(map[string]*KeywordListNode)}
+	m := KeywordManager{list: list.New(), nodeMap: make(map[string]*KeywordListNode), wordMap: make(map[string]map[*KeywordListNode]bool),
+		pinyinMap: make(map[string]*KeywordListNode)}
 	return &m
 }
 
-// get permutation string of @keywords, seperated by space
-func getAllKeywords(keywords []string) []string {
-	var allKeywords []string
-	for i := 0; i < len(keywords); i++ {
-		key := keywords[i]
-		var subKeywords []string
-		for j := 0; j < len(keywords); j++ {
-			if j != i {
-				subKeywords = append(subKeywords, keywords[j])
-			}
-		}
+// getCanonicalKeys is normalized, sorted and deduplicated @keywords, so that keywords in any order have same key.
+func getCanonicalKeys(keywords []string) []string {
+	var keys []string
+	for _, key := range keywords {
+		keys = append(keys, NormalizeChinese(key))
+	}
+	sort.Strings(keys)
 
-		if subKeywords == nil {
-			allKeywords = append(allKeywords, key)
-		} else {
-			allSubKeywords := getAllKeywords(subKeywords)
-			for _, keyStr := range allSubKeywords {
-				allKeywords = append(allKeywords, key+" "+keyStr)
-			}
+	var newKeys []string
+	for i, key := range keys {
+		if i == 0 || key != keys[i-1] {
+			newKeys = append(newKeys, key)
 		}
 	}
 
-	return allKeywords
+	return newKeys
 }
 
 func (m *KeywordManager) getNode(keywords []string) *KeywordListNode {
-	allKeywords := getAllKeywords(keywords)
-	for _, key := range allKeywords {
-		if node := m.nodeMap[key]; node != nil {
-			return node
-		}
+	if node := m.nodeMap[strings.Join(getCanonicalKeys(keywords), " ")]; node != nil {
+		return node
+	}
+
+	// like langyabang or lyb for 琅琊榜
+	if len(keywords) == 1 && isPinyinKey(keywords[0]) {
+		return m.pinyinMap[keywords[0]]
 	}
 
 	return nil
 }
 
-func (m *KeywordManager) setNew(keywords []string, items []*Item) {
+func (m *KeywordManager) setNew(keywords []string, items []*Item, tFetched int64) {
 	if len(m.nodeMap) == maxKeywordsNbr {
 		// remove least recently keywords
 		e := m.list.Front()
 		node := e.Value.(*KeywordListNode)
 
-		delete(m.nodeMap, node.keyStr)
+		delete(m.nodeMap, strings.Join(node.keys, " "))
+		for _, key := range node.keys {
+			delete(m.wordMap[key], node)
+			if len(m.wordMap[key]) == 0 {
+				delete(m.wordMap, key)
+			}
+		}
+		for _, pinyinKey := range node.pinyinKeys {
+			if m.pinyinMap[pinyinKey] == node {
+				delete(m.pinyinMap, pinyinKey)
+			}
+		}
 		m.list.Remove(e)
 	}
 
 	keyStr := strings.Join(keywords, " ")
-	node := KeywordListNode{keyStr: keyStr, items: items}
+	keys := getCanonicalKeys(keywords)
+	node := KeywordListNode{keyStr: keyStr, keys: keys, items: items, tFetched: tFetched}
 	e := m.list.PushBack(&node)
 	node.e = e
 
-	m.nodeMap[keyStr] = &node
+	m.nodeMap[strings.Join(keys, " ")] = &node
+	for _, key := range keys {
+		if m.wordMap[key] == nil {
+			m.wordMap[key] = make(map[*KeywordListNode]bool)
+		}
+		m.wordMap[key][&node] = true
+	}
+
+	if HasChinese(keyStr) {
+		full, initials := GetPinyin(keyStr)
+		node.pinyinKeys = []string{full, initials}
+		for _, pinyinKey := range node.pinyinKeys {
+			if isPinyinKey(pinyinKey) {
+				m.pinyinMap[pinyinKey] = &node
+			}
+		}
+	}
 }
 
-func (m *KeywordManager) setExisting(node *KeywordListNode, items []*Item) {
+func isSameItem(item1, item2 *Item) bool {
+	return item1.OrgName == item2.OrgName && item1.Type == item2.Type && item1.Year == item2.Year
+}
+
+func (m *KeywordManager) setExisting(node *KeywordListNode, items []*Item, tFetched int64) {
 	m.list.MoveToBack(node.e)
+	node.tFetched = tFetched
 
 	var newItems []*Item
 	for _, item := range items {
 		existing := false
 		for _, existingItem := range node.items {
-			if item.OrgName == existingItem.OrgName && item.Type == existingItem.Type {
+			if isSameItem(item, existingItem) {
 				existing = true
 				break
 			}
@@ -114,12 +156,14 @@ func (m *KeywordManager) Set(keywords []string, items []*Item) {
 
 	m.lock.Lock()
 
+	t := time.Now().Unix()
 	node := m.getNode(keywords)
 	if node == nil {
-		m.setNew(keywords, items)
+		m.setNew(keywords, items, t)
 	} else {
-		m.setExisting(node, items)
+		m.setExisting(node, items, t)
 	}
+	m.bDirty = true
 
 	m.lock.Unlock()
 }
@@ -140,6 +184,77 @@ func (m *KeywordManager) Get(keywords []string) []*Item {
 	return items
 }
 
+// getRelatedNodes is getting nodes whose keywords are subset of @keywords, or superset if @bSuperset, the closest first.
+// Cost is number of nodes sharing any keyword, not permutations of keywords.
+func (m *KeywordManager) getRelatedNodes(keywords []string, bSuperset bool) []*KeywordListNode {
+	keys := getCanonicalKeys(keywords)
+
+	// number of @keywords in each node
+	counts := make(map[*KeywordListNode]int)
+	for _, key := range keys {
+		for node := range m.wordMap[key] {
+			counts[node]++
+		}
+	}
+
+	var nodes []*KeywordListNode
+	for node, n := range counts {
+		// subset like walking dead for the walking dead s05, or superset like the walking dead s05 for walking dead
+		if n == len(node.keys) || (bSuperset && n == len(keys)) {
+			nodes = append(nodes, node)
+		}
+	}
+
+	distance := func(node *KeywordListNode) int {
+		d := len(node.keys) - len(keys)
+		if d < 0 {
+			return -d
+		}
+		return d
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		if distance(nodes[i]) != distance(nodes[j]) {
+			return distance(nodes[i]) < distance(nodes[j])
+		}
+		return nodes[i].tFetched > nodes[j].tFetched
+	})
+
+	if len(nodes) > maxRelatedNodes {
+		nodes = nodes[:maxRelatedNodes]
+	}
+
+	return nodes
+}
+
+// GetRelated is getting items of keywords which are subset of @keywords, or superset too if @bSuperset, when Get found nothing.
+// Items should be filtered by user keywords since they are not exactly for @keywords.
+// Superset ones are narrower than @keywords, like the walking dead for dead, so items of @keywords may be missed.
+func (m *KeywordManager) GetRelated(keywords []string, bSuperset bool) []*Item {
+	var items []*Item
+
+	m.lock.RLock()
+
+	for _, node := range m.getRelatedNodes(keywords, bSuperset) {
+		for _, item := range node.items {
+			existing := false
+			for _, existingItem := range items {
+				if isSameItem(item, existingItem) {
+					existing = true
+					break
+				}
+			}
+
+			if !existing {
+				items = append(items, item)
+			}
+		}
+	}
+
+	m.lock.RUnlock()
+
+	return items
+}
+
 // GetKeyStrs is getting all key strings from least recently to most recently.
 func (m *KeywordManager) GetKeyStrs() []string {
 	var keyStrs []string
@@ -155,3 +270,28 @@ func (m *KeywordManager) GetKeyStrs() []string {
 
 	return keyStrs
 }
+
+// GetStaleKeyStrs is getting key strings of items fetched before @tBefore, the oldest first.
+func (m *KeywordManager) GetStaleKeyStrs(tBefore int64) []string {
+	var nodes []*KeywordListNode
+
+	m.lock.RLock()
+
+	for e := m.list.Front(); e != nil; e = e.Next() {
+		node := e.Value.(*KeywordListNode)
+		if node.tFetched < tBefore {
+			nodes = append(nodes, node)
+		}
+	}
+
+	m.lock.RUnlock()
+
+	sort.SliceStable(nodes, func(i, j int) bool { return nodes[i].tFetched < nodes[j].tFetched })
+
+	var keyStrs []string
+	for _, node := range nodes {
+		keyStrs = append(keyStrs, node.keyStr)
+	}
+
+	return keyStrs
+}
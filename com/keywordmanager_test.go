@@ -0,0 +1,100 @@
+package com
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestKeywordManagerCanonicalKey(t *testing.T) {
+	m := NewKeywordManager()
+	m.Set([]string{"walking", "dead"}, []*Item{{Type: SeasonTV, OrgName: "The Walking Dead"}})
+	m.Set([]string{"Dead", "walking", "dead"}, []*Item{{Type: SeasonTV, OrgName: "Fear the Walking Dead"}})
+	m.Set([]string{"權力的遊戲"}, []*Item{{Type: SeasonTV, OrgName: "Game of Thrones", ChName: "权力的游戏"}})
+
+	if len(m.nodeMap) != 2 {
+		t.Fatalf("%d entries, expected 2", len(m.nodeMap))
+	}
+
+	tests := []struct {
+		keywords []string
+		nbr      int
+	}{
+		{[]string{"dead", "walking"}, 2},
+		{[]string{"WALKING", "DEAD"}, 2},
+		{[]string{"权力的游戏"}, 1},
+		{[]string{"quanlideyouxi"}, 1},
+		{[]string{"qldyx"}, 1},
+		{[]string{"walking"}, 0},
+	}
+
+	for _, test := range tests {
+		if items := m.Get(test.keywords); len(items) != test.nbr {
+			t.Errorf("Get(%q) = %d items, expected %d", test.keywords, len(items), test.nbr)
+		}
+	}
+
+	// user order is kept for searching again
+	if keyStrs := m.GetKeyStrs(); keyStrs[0] != "walking dead" {
+		t.Errorf("key strings: %q", keyStrs)
+	}
+}
+
+func TestKeywordManagerEviction(t *testing.T) {
+	m := NewKeywordManager()
+	for i := 0; i < maxKeywordsNbr+10; i++ {
+		m.Set([]string{"title", fmt.Sprintf("n%d", i)}, []*Item{{OrgName: "Title"}})
+	}
+
+	if len(m.nodeMap) != maxKeywordsNbr || m.list.Len() != maxKeywordsNbr {
+		t.Fatalf("%d entries in map and %d in list, expected %d", len(m.nodeMap), m.list.Len(), maxKeywordsNbr)
+	}
+
+	// the least recently ones are removed from word map too
+	if len(m.wordMap["title"]) != maxKeywordsNbr {
+		t.Errorf("%d entries of title in word map, expected %d", len(m.wordMap["title"]), maxKeywordsNbr)
+	}
+	for i := 0; i < 10; i++ {
+		if key := fmt.Sprintf("n%d", i); m.wordMap[key] != nil || m.Get([]string{"title", key}) != nil {
+			t.Errorf("%s is not removed", key)
+		}
+	}
+	if m.Get([]string{"title", "n10"}) == nil {
+		t.Errorf("n10 is removed")
+	}
+}
+
+func TestKeywordManagerRelated(t *testing.T) {
+	m := NewKeywordManager()
+	m.Set([]string{"walking", "dead"}, []*Item{{Type: SeasonTV, OrgName: "The Walking Dead"}})
+	m.Set([]string{"fear", "walking", "dead"}, []*Item{{Type: SeasonTV, OrgName: "Fear the Walking Dead"}})
+
+	tests := []struct {
+		keywords  []string
+		bSuperset bool
+		orgNames  string
+	}{
+		// subset, broader entries
+		{[]string{"dead", "walking", "s05"}, false, "The Walking Dead"},
+		{[]string{"fear", "walking", "dead", "s05"}, false, "Fear the Walking Dead,The Walking Dead"},
+		{[]string{"dead"}, false, ""},
+
+		// superset, narrower entries
+		{[]string{"dead"}, true, "The Walking Dead,Fear the Walking Dead"},
+		{[]string{"fear"}, true, "Fear the Walking Dead"},
+		{[]string{"dead", "man"}, true, ""},
+	}
+
+	for _, test := range tests {
+		orgNames := ""
+		for i, item := range m.GetRelated(test.keywords, test.bSuperset) {
+			if i > 0 {
+				orgNames += ","
+			}
+			orgNames += item.OrgName
+		}
+
+		if orgNames != test.orgNames {
+			t.Errorf("GetRelated(%q, %v) = %q, expected %q", test.keywords, test.bSuperset, orgNames, test.orgNames)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package com
+
+import (
+	"bytes"
+	"encoding/base32"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"sync"
+	"text/template"
+)
+
+const aichHashSize = 20
+
+// LinkFormat is a custom link format rendered from file link by text template, e.g. magnet.
+// Template data is LinkTemplateData.
+type LinkFormat struct {
+	Name     string // key of link in JSON
+	Template string
+
+	tmpl *template.Template
+}
+
+// LinkTemplateData is data for link format template.
+type LinkTemplateData struct {
+	Name        string
+	EncodedName string // URL encoded name
+	Size        uint64
+	Hash        string // ED2K hash in base16
+	AICHHash    string // AICH hash in base32, empty if unknown
+	Ed2k        string
+	Magnet      string
+}
+
+var linkFormats []*LinkFormat
+var linkFormatsLock sync.RWMutex
+
+// SetLinkFormats is setting link formats which will be rendered in file link JSON.
+func SetLinkFormats(formats []*LinkFormat) error {
+	for _, format := range formats {
+		tmpl, err := template.New(format.Name).Parse(format.Template)
+		if err != nil {
+			return fmt.Errorf("link format %s: %s", format.Name, err)
+		}
+		format.tmpl = tmpl
+	}
+
+	linkFormatsLock.Lock()
+	linkFormats = formats
+	linkFormatsLock.Unlock()
+
+	return nil
+}
+
+// LoadLinkFormats is loading link formats from JSON file, like [{"Name": "magnet", "Template": "{{.Magnet}}"}].
+func LoadLinkFormats(fileName string) error {
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return err
+	}
+
+	var formats []*LinkFormat
+	if err := json.Unmarshal(data, &formats); err != nil {
+		return err
+	}
+
+	return SetLinkFormats(formats)
+}
+
+// GetAICHHashStr is getting AICH hash in base32, which is used in links.
+func GetAICHHashStr(aichHash []byte) string {
+	if len(aichHash) != aichHashSize {
+		return ""
+	}
+
+	return base32.StdEncoding.EncodeToString(aichHash)
+}
+
+// ParseAICHHashStr is parsing AICH hash from base32.
+func ParseAICHHashStr(s string) []byte {
+	aichHash, err := base32.StdEncoding.DecodeString(s)
+	if err != nil || len(aichHash) != aichHashSize {
+		return nil
+	}
+
+	return aichHash
+}
+
+// GetMagnetLink is getting magnet link with ED2K hash and AICH hash if known.
+func (f *Ed2kFileLink) GetMagnetLink() string {
+	hash := ConvertEd2kHash32(f.Hash)
+	link := fmt.Sprintf("magnet:?xt=urn:ed2k:%s", encodeBase16(hash[:]))
+
+	if aich := GetAICHHashStr(f.AICHHash); aich != "" {
+		link += "&xt=urn:aich:" + aich
+	}
+
+	link += fmt.Sprintf("&xl=%d&dn=%s", f.Size, url.QueryEscape(f.Name))
+
+	return link
+}
+
+func (f *Ed2kFileLink) getLinkTemplateData() *LinkTemplateData {
+	hash := ConvertEd2kHash32(f.Hash)
+	return &LinkTemplateData{
+		Name:        f.Name,
+		EncodedName: encodeURLUtf8(stripInvalidFileNameChars(f.Name)),
+		Size:        f.Size,
+		Hash:        encodeBase16(hash[:]),
+		AICHHash:    GetAICHHashStr(f.AICHHash),
+		Ed2k:        f.GetEd2kLink(),
+		Magnet:      f.GetMagnetLink()}
+}
+
+// GetLinks is getting links of all link formats set, nil if no link format.
+func (f *Ed2kFileLink) GetLinks() map[string]string {
+	linkFormatsLock.RLock()
+	defer linkFormatsLock.RUnlock()
+
+	if len(linkFormats) == 0 {
+		return nil
+	}
+
+	data := f.getLinkTemplateData()
+	links := make(map[string]string)
+	for _, format := range linkFormats {
+		buf := bytes.Buffer{}
+		if err := format.tmpl.Execute(&buf, data); err != nil {
+			HhjLog.Warningf("Render link format %s failed: %s", format.Name, err)
+			continue
+		}
+
+		links[format.Name] = buf.String()
+	}
+
+	return links
+}
@@ -22,6 +22,7 @@ const (
 const (
 	minTargetKeywordSize  = 3
 	minPrimaryKeywordSize = 3
+	maxGroupKeywordNbr    = 3 // keywords of each item for multi-keyword search
 )
 
 // MyKeyword is keyword in my system, low cases except @OrgKeywords
@@ -31,6 +32,7 @@ type MyKeyword struct {
 	SearchKeywords []string // search keywords of name, for DouBan search
 	NameKeywords   []string // keywords of name based on user input
 	Season         int      // 0: all seasons for TV and movies
+	Year           int      // release year, 0 if any
 }
 
 // Item is getting from internet, e.g. DouBan
@@ -39,11 +41,15 @@ type Item struct {
 	OrgName     string
 	ChName      string
 	OtherChName string
+	Year        int    // release year, 0 if unknown
+	PosterURL   string // empty if unknown
+	Seasons     int    // number of seasons for TV, 0 if unknown
 }
 
 // MyKeywordStruct is used for KAD search with multiple target keywords.
 type MyKeywordStruct struct {
-	TargetKeywords []string // For KAD search
+	TargetKeywords []string   // For KAD search
+	KeywordGroups  [][]string // For KAD multi-keyword search, keywords of each item
 
 	MyKeyword *MyKeyword // from user
 	Items     []*Item    // from Internet or database
@@ -52,7 +58,12 @@ type MyKeywordStruct struct {
 var theWords = map[string]bool{"the": true, "these": true, "that": true, "a": true, "this": true,
 	"he": true, "she": true, "we": true, "you": true, "us": true, "his": true, "her": true, "it": true, "my": true, "our": true,
 	"no": true, "yes": true, "not": true, "is": true, "are": true,
-	"in": true, "on": true, "of": true}
+	"in": true, "on": true, "of": true, "and": true}
+
+// IsStopWord is true if @word is common English word, like the, which is not counted for titles.
+func IsStopWord(word string) bool {
+	return theWords[word]
+}
 
 var theChDigits = map[string]int{"零": 0, "一": 1, "二": 2, "三": 3, "四": 4, "五": 5, "六": 6, "七": 7, "八": 8, "九": 9}
 
@@ -103,8 +114,30 @@ func NewMyKeyword(keywords []string) *MyKeyword {
 			continue
 		}
 
+		// Traditional Chinese is same as Simplified
+		key = ToSimplified(key)
+
 		// check if season or episode keyword.
 		// if so, only extract season or episode information, without thinking it as keyword.
+		if key == "year" {
+			// assume next keyword is specific year
+			if i < len(keywords)-1 {
+				year, ok := parseDigit(keywords[i+1], false)
+				if ok && isValidYear(year) {
+					myKeyword.Year = year
+					ignoreI = i + 1
+					continue
+				}
+			}
+		} else if text := []rune(key); len(text) > 1 && text[len(text)-1] == '年' {
+			// like 2017年
+			year, ok := parseDigit(string(text[:len(text)-1]), false)
+			if ok && isValidYear(year) {
+				myKeyword.Year = year
+				continue
+			}
+		}
+
 		if key == "season" {
 			// assume next keyword is specific season
 			if i < len(keywords)-1 {
@@ -162,12 +195,20 @@ func FilterItems(m []*Item, myKeyword *MyKeyword) []*Item {
 	// filter
 	var items []*Item
 	for _, item := range m {
-		orgName := strings.ToLower(item.OrgName)
+		if !isYearMatched(item.Year, myKeyword.Year) {
+			continue
+		}
+
+		orgName := NormalizeChinese(item.OrgName)
+		chName := NormalizeChinese(item.ChName)
+		otherChName := NormalizeChinese(item.OtherChName)
 		bSatisfied := true
 		for _, key := range myKeyword.NameKeywords {
+			key = NormalizeChinese(key)
 			if strings.Index(orgName, key) == -1 &&
-				strings.Index(item.ChName, key) == -1 &&
-				strings.Index(item.OtherChName, key) == -1 {
+				strings.Index(chName, key) == -1 &&
+				strings.Index(otherChName, key) == -1 &&
+				!MatchPinyin(item.ChName, key) && !MatchPinyin(item.OtherChName, key) {
 				bSatisfied = false
 				break
 			}
@@ -269,7 +310,36 @@ func NewMyKeywordStruct(myKeyword *MyKeyword, items []*Item) *MyKeywordStruct {
 		return nil
 	}
 
-	return &MyKeywordStruct{TargetKeywords: targetKeywords, MyKeyword: myKeyword, Items: items}
+	return &MyKeywordStruct{TargetKeywords: targetKeywords, KeywordGroups: getKeywordGroups(items), MyKeyword: myKeyword, Items: items}
+}
+
+// get keyword groups for KAD multi-keyword search, same group of items is only once
+func getKeywordGroups(items []*Item) [][]string {
+	groupMap := make(map[string]bool)
+	var groups [][]string
+	for _, item := range items {
+		keywordSlice, _ := GetPrimaryKeywords(item.OrgName)
+
+		var group []string
+		for _, key := range keywordSlice {
+			if len(key) >= minTargetKeywordSize {
+				group = append(group, key)
+				if len(group) == maxGroupKeywordNbr {
+					break
+				}
+			}
+		}
+
+		groupKey := strings.Join(group, " ")
+		if len(group) == 0 || groupMap[groupKey] {
+			continue
+		}
+
+		groupMap[groupKey] = true
+		groups = append(groups, group)
+	}
+
+	return groups
 }
 
 // get target keywords for KAD
@@ -300,6 +370,11 @@ func getTargetKeywords(items []*Item) []string {
 	var targetKeywords []string
 	for keyword := range targetKeywordMap {
 		targetKeywords = append(targetKeywords, keyword)
+
+		// many Chinese files are published in Traditional
+		if tKeyword := ToTraditional(keyword); tKeyword != keyword && !targetKeywordMap[tKeyword] {
+			targetKeywords = append(targetKeywords, tKeyword)
+		}
 	}
 
 	return targetKeywords
@@ -0,0 +1,227 @@
+package com
+
+import (
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ReleaseInfo is quality information parsed from release name, empty if unknown.
+type ReleaseInfo struct {
+	Resolution string   `json:",omitempty"` // e.g. 720p, 1080p, 2160p
+	Source     string   `json:",omitempty"` // e.g. BluRay, WEB-DL, HDTV, DVDRip
+	VideoCodec string   `json:",omitempty"` // e.g. H.264, H.265
+	AudioCodec string   `json:",omitempty"` // e.g. AAC, AC3, DTS
+	HDR        []string `json:",omitempty"` // e.g. HDR10, DV
+	Languages  []string `json:",omitempty"` // audio languages, e.g. Mandarin, Cantonese
+	Subtitles  []string `json:",omitempty"` // Chinese subtitle markers, e.g. 中字, 双语, 简繁
+	Group      string   `json:",omitempty"` // release group
+	Part       int      `json:",omitempty"` // like CD1, Part2, 0 if not split
+}
+
+// one pattern to value, patterns are in lower case
+type releasePattern struct {
+	re    *regexp.Regexp
+	value string
+}
+
+// separators around tokens in release names
+const releaseSep = `(?:^|$|[\s._\-\[\]()【】{}+,])`
+
+func newReleasePatterns(patterns ...string) []*releasePattern {
+	var rps []*releasePattern
+	for i := 0; i+1 < len(patterns); i += 2 {
+		re := regexp.MustCompile(releaseSep + "(?:" + patterns[i] + ")" + releaseSep)
+		rps = append(rps, &releasePattern{re: re, value: patterns[i+1]})
+	}
+
+	return rps
+}
+
+// Chinese markers have no separators
+func newReleaseMarkers(patterns ...string) []*releasePattern {
+	var rps []*releasePattern
+	for i := 0; i+1 < len(patterns); i += 2 {
+		rps = append(rps, &releasePattern{re: regexp.MustCompile(patterns[i]), value: patterns[i+1]})
+	}
+
+	return rps
+}
+
+// in priority order, the first matched one is taken
+var releaseResolutions = newReleasePatterns(
+	`4320p|8k`, "4320p",
+	`2160p|4k|uhd|3840x2160`, "2160p",
+	`1440p|2560x1440`, "1440p",
+	`1080p|1920x1080|fhd`, "1080p",
+	`1080i`, "1080i",
+	`720p|1280x720`, "720p",
+	`576p|576i`, "576p",
+	`480p|480i|848x480|640x480`, "480p",
+)
+
+var releaseSources = newReleasePatterns(
+	`bd-?remux|blu-?ray[ .]?remux`, "BluRay Remux",
+	`blu-?ray|bd25|bd50|bdmv`, "BluRay",
+	`bd-?rip|br-?rip`, "BDRip",
+	`web-?dl|webdl`, "WEB-DL",
+	`web-?rip`, "WEBRip",
+	`web`, "WEB",
+	`hdtv|hdtvrip`, "HDTV",
+	`hd-?rip`, "HDRip",
+	`dvd-?rip`, "DVDRip",
+	`dvd5|dvd9|dvd|dvd-?scr`, "DVD",
+	`tv-?rip`, "TVRip",
+	`hd-?cam|cam-?rip|cam|hd-?ts|telesync|ts`, "CAM",
+	`hd-?tc|telecine|tc`, "TC",
+)
+
+var releaseVideoCodecs = newReleasePatterns(
+	`x265|h\.?265|hevc`, "H.265",
+	`x264|h\.?264|avc`, "H.264",
+	`av1`, "AV1",
+	`vc-?1`, "VC-1",
+	`xvid`, "XviD",
+	`divx`, "DivX",
+	`mpeg-?2`, "MPEG-2",
+	`rmvb`, "RMVB",
+)
+
+var releaseAudioCodecs = newReleasePatterns(
+	`truehd(?:[ .]?atmos)?(?:[ .]?\d\.\d)?`, "TrueHD",
+	`dts-?hd(?:[ .-]?ma)?(?:[ .]?\d\.\d)?|dts-?x`, "DTS-HD",
+	`dts(?:[ .]?\d\.\d)?`, "DTS",
+	`e-?ac-?3|ddp(?:[ .]?\d\.\d)?|dd\+(?:[ .]?\d\.\d)?`, "E-AC3",
+	`ac-?3|dd(?:[ .]?\d\.\d)?`, "AC3",
+	`flac`, "FLAC",
+	`lpcm|pcm`, "LPCM",
+	`aac(?:[ .]?\d\.\d)?`, "AAC",
+	`opus`, "Opus",
+	`mp3`, "MP3",
+)
+
+// all matched ones are taken
+var releaseHDRs = newReleasePatterns(
+	`dolby[ .]?vision|dovi|dv`, "DV",
+	`hdr10\+|hdr10plus`, "HDR10+",
+	`hdr10`, "HDR10",
+	`hdr`, "HDR",
+	`hlg`, "HLG",
+)
+
+var releaseLanguages = newReleaseMarkers(
+	`国语|国配|国粤|普通话|mandarin`, "Mandarin",
+	`粤语|粤配|国粤|cantonese`, "Cantonese",
+	`台配`, "Taiwanese Mandarin",
+	`英语|english`, "English",
+	`日语|japanese`, "Japanese",
+	`韩语|korean`, "Korean",
+)
+
+var releaseSubtitles = newReleaseMarkers(
+	`中字|中文字幕|中文字母`, "中字",
+	`双语|双字|中英`, "双语",
+	`简繁`, "简繁",
+	`简体|简中`, "简体",
+	`繁体|繁中`, "繁体",
+	`内封|内嵌|外挂`, "内封",
+)
+
+// like CD1, Disc 2, Part.3, pt2
+var releasePartRegexp = regexp.MustCompile(releaseSep + `(?:cd|disc|disk|part|pt)[ .]?(\d{1,2})` + releaseSep)
+
+// release group is like -GROUP at the end, or [Group] at the beginning for fansubs
+var releaseTailGroupRegexp = regexp.MustCompile(`-([A-Za-z0-9@]{2,20})$`)
+var releaseHeadGroupRegexp = regexp.MustCompile(`^(?:\[([^\[\]]{2,30})\]|【([^【】]{2,30})】)`)
+
+func matchReleasePattern(name string, rps []*releasePattern) string {
+	for _, rp := range rps {
+		if rp.re.MatchString(name) {
+			return rp.value
+		}
+	}
+
+	return ""
+}
+
+func matchReleasePatterns(name string, rps []*releasePattern) []string {
+	var values []string
+	valueMap := make(map[string]bool)
+	for _, rp := range rps {
+		if rp.re.MatchString(name) && !valueMap[rp.value] {
+			valueMap[rp.value] = true
+			values = append(values, rp.value)
+		}
+	}
+
+	return values
+}
+
+// ParseReleaseName is parsing quality information from release name.
+func ParseReleaseName(name string) *ReleaseInfo {
+	baseName := strings.TrimSuffix(name, path.Ext(name))
+	lowerName := strings.ToLower(baseName)
+
+	info := ReleaseInfo{
+		Resolution: matchReleasePattern(lowerName, releaseResolutions),
+		Source:     matchReleasePattern(lowerName, releaseSources),
+		VideoCodec: matchReleasePattern(lowerName, releaseVideoCodecs),
+		AudioCodec: matchReleasePattern(lowerName, releaseAudioCodecs),
+		HDR:        matchReleasePatterns(lowerName, releaseHDRs),
+		Languages:  matchReleasePatterns(lowerName, releaseLanguages),
+		Subtitles:  matchReleasePatterns(lowerName, releaseSubtitles),
+		Group:      getReleaseGroup(baseName)}
+
+	// RMVB is known by extension
+	if info.VideoCodec == "" && strings.ToLower(path.Ext(name)) == ".rmvb" {
+		info.VideoCodec = "RMVB"
+	}
+
+	if match := releasePartRegexp.FindStringSubmatch(lowerName); match != nil {
+		info.Part, _ = strconv.Atoi(match[1])
+	}
+
+	return &info
+}
+
+// endsWithReleasePattern is true if one of @rps matches at the end of @name, like WEB-DL for DL.
+func endsWithReleasePattern(name string, rps []*releasePattern) bool {
+	for _, rp := range rps {
+		for _, loc := range rp.re.FindAllStringIndex(name, -1) {
+			if loc[1] == len(name) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func getReleaseGroup(baseName string) string {
+	if match := releaseTailGroupRegexp.FindStringSubmatch(baseName); match != nil {
+		group := match[1]
+
+		// not group, like WEB-DL, DTS-HD or x264-1080p
+		lowerGroup := strings.ToLower(group)
+		lowerTail := strings.ToLower(baseName[strings.LastIndexAny(baseName, " ._[]()【】")+1:])
+		if matchReleasePattern(lowerGroup, releaseResolutions) == "" &&
+			matchReleasePattern(lowerGroup, releaseSources) == "" &&
+			matchReleasePattern(lowerGroup, releaseVideoCodecs) == "" &&
+			matchReleasePattern(lowerGroup, releaseAudioCodecs) == "" &&
+			!endsWithReleasePattern(lowerTail, releaseSources) &&
+			!endsWithReleasePattern(lowerTail, releaseAudioCodecs) &&
+			strings.Trim(lowerGroup, digits) != "" {
+			return group
+		}
+	}
+
+	if match := releaseHeadGroupRegexp.FindStringSubmatch(baseName); match != nil {
+		if match[1] != "" {
+			return match[1]
+		}
+		return match[2]
+	}
+
+	return ""
+}
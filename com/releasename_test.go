@@ -0,0 +1,41 @@
+package com
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseReleaseName(t *testing.T) {
+	tests := []struct {
+		name string
+		want ReleaseInfo
+	}{
+		{"The.Walking.Dead.S01E01.1080p.BluRay.x264.DTS-HD.MA.5.1-GROUP.mkv",
+			ReleaseInfo{Resolution: "1080p", Source: "BluRay", VideoCodec: "H.264", AudioCodec: "DTS-HD", Group: "GROUP"}},
+		{"Inception.2010.2160p.UHD.BluRay.REMUX.HDR10.HEVC.TrueHD.Atmos.7.1-FraMeSToR.mkv",
+			ReleaseInfo{Resolution: "2160p", Source: "BluRay Remux", VideoCodec: "H.265", AudioCodec: "TrueHD",
+				HDR: []string{"HDR10"}, Group: "FraMeSToR"}},
+		{"Chernobyl.S01E02.720p.WEB-DL.DDP5.1.H.264-NTb.mkv",
+			ReleaseInfo{Resolution: "720p", Source: "WEB-DL", VideoCodec: "H.264", AudioCodec: "E-AC3", Group: "NTb"}},
+		{"[HorribleSubs] One Punch Man - 12 [720p].mkv",
+			ReleaseInfo{Resolution: "720p", Group: "HorribleSubs"}},
+		{"【悠哈璃羽字幕社】琅琊榜[国粤双语中字].rmvb",
+			ReleaseInfo{VideoCodec: "RMVB", Languages: []string{"Mandarin", "Cantonese"},
+				Subtitles: []string{"中字", "双语"}, Group: "悠哈璃羽字幕社"}},
+		{"Inception.2010.DVDRip.XviD.CD1.avi",
+			ReleaseInfo{Source: "DVDRip", VideoCodec: "XviD", Part: 1}},
+
+		// not groups
+		{"Inception.2010.1080p.WEB-DL.mkv", ReleaseInfo{Resolution: "1080p", Source: "WEB-DL"}},
+		{"Inception.2010.x264-1080p.mkv", ReleaseInfo{Resolution: "1080p", VideoCodec: "H.264"}},
+		{"Inception.2010.720p.BluRay.DTS-HD.mkv", ReleaseInfo{Resolution: "720p", Source: "BluRay", AudioCodec: "DTS-HD"}},
+		{"Inception.2010.720p.HDTV-GROUP.mkv", ReleaseInfo{Resolution: "720p", Source: "HDTV", Group: "GROUP"}},
+		{"Inception-2010.mkv", ReleaseInfo{}},
+	}
+
+	for _, test := range tests {
+		if info := ParseReleaseName(test.name); !reflect.DeepEqual(*info, test.want) {
+			t.Errorf("ParseReleaseName(%s) = %+v, want %+v", test.name, *info, test.want)
+		}
+	}
+}
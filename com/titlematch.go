@@ -0,0 +1,273 @@
+package com
+
+import (
+	"regexp"
+	"strings"
+)
+
+// TitleMatchThreshold is minimal score of MatchTitle for file name to be thought as the title, from 0 to 1.
+var TitleMatchThreshold = 0.6
+
+// Latin letters with accents to ASCII, like Pokémon to pokemon
+var titleFoldFrom = []rune("àáâãäåāèéêëēìíîïīòóôõöøōùúûüūýÿñçß")
+var titleFoldTo = []rune("aaaaaaaeeeeeiiiiiooooooouuuuuyyncs")
+
+func foldTitleChar(c rune) rune {
+	for i, from := range titleFoldFrom {
+		if c == from {
+			return titleFoldTo[i]
+		}
+	}
+
+	return c
+}
+
+// getTitleTokens is splitting title or file name to lower-case tokens.
+// Traditional Chinese is Simplified, apostrophes are removed like Marvel's to marvels, & is and,
+// and single letters are joined like S.H.I.E.L.D. to shield.
+func getTitleTokens(s string) []string {
+	s = NormalizeChinese(s)
+	s = strings.NewReplacer("'", "", "’", "", "&", " and ", "+", " ").Replace(s)
+	s = strings.Map(foldTitleChar, s)
+
+	var tokens []string
+	letters := ""
+	for _, token := range Split2Keywords(s) {
+		if len(token) == 1 && token[0] >= 'a' && token[0] <= 'z' {
+			letters += token
+			continue
+		}
+
+		if letters != "" {
+			tokens = append(tokens, letters)
+			letters = ""
+		}
+		tokens = append(tokens, token)
+	}
+	if letters != "" {
+		tokens = append(tokens, letters)
+	}
+
+	return tokens
+}
+
+// removeStopWords is removing articles and other stop words, unless all are stop words like It or Us.
+func removeStopWords(tokens []string) []string {
+	var newTokens []string
+	for _, token := range tokens {
+		if !theWords[token] {
+			newTokens = append(newTokens, token)
+		}
+	}
+
+	if len(newTokens) == 0 {
+		return tokens
+	}
+
+	return newTokens
+}
+
+// HasChinese is true if @s has any Chinese character.
+func HasChinese(s string) bool {
+	for _, c := range s {
+		if IsChinese(c) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// minimal title tokens of abbreviation, like svu for Special Victims Unit
+const minTitleAbbrTokens = 2
+
+// words after title in release names, like S01E01, 720p, x264, Extended
+var titleReleaseRegexp = regexp.MustCompile(`^(?:\d+|s\d{1,2}(?:e\d{1,3})?|e\d{1,3}|ep\d{1,3}|\d{1,2}x\d{1,3}|v\d|\d{3,4}[pi])$`)
+var titleReleaseWords = map[string]bool{"complete": true, "season": true, "series": true, "extended": true, "uncut": true,
+	"unrated": true, "directors": true, "cut": true, "remastered": true, "imax": true, "proper": true, "repack": true,
+	"internal": true, "limited": true, "edition": true, "theatrical": true,
+	"mkv": true, "avi": true, "mp4": true, "rmvb": true, "rm": true, "wmv": true}
+
+// leading groups or sites in brackets, like [HorribleSubs] or 【字幕组】
+var titleHeadRegexp = regexp.MustCompile(`^\s*(?:\[[^\[\]]*\]|【[^【】]*】|\([^()]*\))`)
+
+func isTitleReleaseToken(token string) bool {
+	return titleReleaseRegexp.MatchString(token) || titleReleaseWords[token] ||
+		matchReleasePattern(token, releaseResolutions) != "" ||
+		matchReleasePattern(token, releaseSources) != "" ||
+		matchReleasePattern(token, releaseVideoCodecs) != "" ||
+		matchReleasePattern(token, releaseAudioCodecs) != ""
+}
+
+func hasTitleToken(name string) bool {
+	for _, token := range getTitleTokens(name) {
+		if !isTitleReleaseToken(token) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getTitleAbbr is initials of Latin title tokens, like svu for special victims unit.
+func getTitleAbbr(tokens []string) string {
+	abbr := ""
+	for _, token := range tokens {
+		if HasChinese(token) {
+			return ""
+		}
+		abbr += token[:1]
+	}
+
+	return abbr
+}
+
+// matchTitleToken is finding title token in name tokens from @start, returning index of matched and number of title tokens matched.
+// Chinese token matches part of name token since there is no space between Chinese words, e.g. 捉妖记 in 捉妖记2国语.
+// Latin tokens must be equal, be joined like spider man to spiderman, or be abbreviated like special victims unit to svu.
+func matchTitleToken(titleTokens []string, i int, nameTokens []string, start int) (int, int) {
+	token := titleTokens[i]
+	chinese := HasChinese(token)
+	for j := start; j < len(nameTokens); j++ {
+		if nameTokens[j] == token || (chinese && strings.Index(nameTokens[j], token) != -1) {
+			return j, 1
+		}
+
+		if i+1 < len(titleTokens) && nameTokens[j] == token+titleTokens[i+1] {
+			return j, 2
+		}
+
+		for n := len(titleTokens) - i; n >= minTitleAbbrTokens; n-- {
+			if nameTokens[j] == getTitleAbbr(titleTokens[i:i+n]) {
+				return j, n
+			}
+		}
+	}
+
+	return -1, 0
+}
+
+// getExtraTitleChars is characters of name tokens not matched but looking like part of another title,
+// before and between matched ones, and after them until release information like S01E01 or 720p.
+// Stop words, release information and Chinese names are not extra, since Chinese words have no spaces and
+// Chinese title is often with other words, like 琅琊榜第01集.
+// If @bOuterFree, the nearest extra token before and after the title is not counted, since it's often site or channel,
+// like yyets.the.walking.dead or sherlock.bbc.s01e01. So sequel of one more word like Fear the Walking Dead is matched too.
+func getExtraTitleChars(nameTokens []string, aligned map[int]bool, bOuterFree bool) int {
+	first, last := -1, -1
+	for j := range nameTokens {
+		if aligned[j] {
+			if first == -1 {
+				first = j
+			}
+			last = j
+		}
+	}
+	if first == -1 {
+		return 0
+	}
+
+	isExtra := func(j int) bool {
+		token := nameTokens[j]
+		return !aligned[j] && !theWords[token] && !HasChinese(token) && !isTitleReleaseToken(token)
+	}
+
+	// free ones
+	freeLeading, freeTrailing := -1, -1
+	if bOuterFree {
+		for j := first - 1; j >= 0 && freeLeading == -1; j-- {
+			if isExtra(j) {
+				freeLeading = j
+			}
+		}
+		for j := last + 1; j < len(nameTokens) && freeTrailing == -1 && !isTitleReleaseToken(nameTokens[j]); j++ {
+			if isExtra(j) {
+				freeTrailing = j
+			}
+		}
+	}
+
+	extra := 0
+	for j, token := range nameTokens {
+		if j > last && isTitleReleaseToken(token) {
+			break
+		}
+
+		if isExtra(j) && j != freeLeading && j != freeTrailing {
+			extra += len([]rune(token))
+		}
+	}
+
+	return extra
+}
+
+// MatchTitle is scoring how well file name matches title, from 0 to 1.
+// Title tokens are aligned with name tokens in order. Score is matched characters of title without stop words,
+// minus characters of title tokens out of order and extra name tokens, divided by characters of title and extra tokens.
+// So Fear the Walking Dead or The Dark Knight Rises is not The Walking Dead or The Dark Knight.
+func MatchTitle(name string, title string) float64 {
+	for titleHeadRegexp.MatchString(name) {
+		stripped := titleHeadRegexp.ReplaceAllString(name, "")
+		if !hasTitleToken(stripped) { // title in brackets, like [捉妖记].rmvb
+			break
+		}
+		name = stripped
+	}
+
+	titleTokens := removeStopWords(getTitleTokens(title))
+	nameTokens := getTitleTokens(name)
+	if len(titleTokens) == 0 || len(nameTokens) == 0 {
+		return 0
+	}
+
+	total, matched := 0, 0
+	for _, token := range titleTokens {
+		total += len([]rune(token))
+	}
+
+	start := 0
+	aligned := make(map[int]bool)
+	for i := 0; i < len(titleTokens); {
+		j, n := matchTitleToken(titleTokens, i, nameTokens, start)
+		if j == -1 {
+			// out of order is another title, like Dead Man Walking for The Walking Dead
+			if j, _ := matchTitleToken(titleTokens, i, nameTokens[:start], 0); j != -1 {
+				matched -= len([]rune(titleTokens[i]))
+			}
+
+			// dropped word, like Marvel's in Agents.of.SHIELD
+			i++
+			continue
+		}
+
+		for _, token := range titleTokens[i : i+n] {
+			matched += len([]rune(token))
+		}
+		aligned[j] = true
+		start = j + 1
+		i += n
+	}
+
+	// title of stop words is strict, like It for It Follows, stop words are kept only if all are
+	extra := 0
+	if !HasChinese(title) {
+		extra = getExtraTitleChars(nameTokens, aligned, !theWords[titleTokens[0]])
+	}
+	matched -= extra
+	if matched < 0 {
+		return 0
+	}
+
+	return float64(matched) / float64(total+extra)
+}
+
+// IsTitleMatched is true if score of MatchTitle reaches TitleMatchThreshold.
+// Title of one token must be matched fully.
+func IsTitleMatched(name string, title string) bool {
+	score := MatchTitle(name, title)
+	if len(removeStopWords(getTitleTokens(title))) == 1 {
+		return score == 1
+	}
+
+	return score >= TitleMatchThreshold
+}
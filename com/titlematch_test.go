@@ -0,0 +1,73 @@
+package com
+
+import "testing"
+
+func TestIsTitleMatched(t *testing.T) {
+	tests := []struct {
+		name    string
+		title   string
+		matched bool
+	}{
+		// dropped, joined, abbreviated and accented words
+		{"the.walking.dead.s05e01.720p.hdtv.x264", "The Walking Dead", true},
+		{"walking.dead.s05e01", "The Walking Dead", true},
+		{"marvels.agents.of.shield.s01e01.720p", "Marvel's Agents of S.H.I.E.L.D.", true},
+		{"agents.of.s.h.i.e.l.d.s01e01", "Marvel's Agents of S.H.I.E.L.D.", true},
+		{"spiderman.homecoming.2017.1080p", "Spider-Man: Homecoming", true},
+		{"law.and.order.svu.s20e01", "Law & Order: Special Victims Unit", true},
+		{"law.&.order.special.victims.unit.s20e01", "Law & Order: Special Victims Unit", true},
+		{"pokemon.detective.pikachu.2019", "Pokémon Detective Pikachu", true},
+		{"inception.2010.extended.directors.cut.bluray", "Inception", true},
+		{"[horriblesubs] shingeki no kyojin - 01 [720p].mkv", "Shingeki no Kyojin", true},
+		{"行尸走肉.the.walking.dead.s01e01", "The Walking Dead", true},
+		{"[电影天堂www.dy2018.com]捉妖记2国语中字.rmvb", "捉妖记", true},
+		{"[捉妖记].rmvb", "捉妖记", true},
+		{"琅琊榜第01集", "琅琊榜", true},
+		{"琅琊榜.nirvana.in.fire.ep01", "琅琊榜", true},
+		{"it.2017.1080p", "It", true},
+
+		// one word around title is site or channel, so sequel of one more word isn't told apart
+		{"sherlock.bbc.s01e01.720p.mkv", "Sherlock", true},
+		{"yyets.the.walking.dead.s01e01.mkv", "The Walking Dead", true},
+		{"fear.the.walking.dead.s01e01", "The Walking Dead", true},
+		{"the.dark.knight.rises.2012.1080p", "The Dark Knight", true},
+
+		// another title
+		{"sherlock.holmes.a.game.of.shadows.2011", "Sherlock", false},
+		{"batman.the.dark.knight.returns.part.2.2013", "The Dark Knight", false},
+		{"star.wars.the.last.jedi.2017", "Star Wars", false},
+		{"dead.man.walking.1995", "The Walking Dead", false},
+		{"it.follows.2014", "It", false},
+		{"law.and.order.s20e01", "Law & Order: Special Victims Unit", false},
+	}
+
+	for _, test := range tests {
+		if matched := IsTitleMatched(test.name, test.title); matched != test.matched {
+			t.Errorf("IsTitleMatched(%q, %q) = %v, score %.2f", test.name, test.title, matched, MatchTitle(test.name, test.title))
+		}
+	}
+}
+
+func TestMatchTitleScore(t *testing.T) {
+	tests := []struct {
+		name  string
+		title string
+		score float64
+	}{
+		{"the.walking.dead.s05e01", "The Walking Dead", 1},
+		{"law.and.order.svu", "Law & Order: Special Victims Unit", 1},
+		{"sherlock.bbc.s01e01.720p.mkv", "Sherlock", 1},
+		{"yyets.the.walking.dead.s01e01.mkv", "The Walking Dead", 1},
+		{"www.yyets.the.walking.dead", "The Walking Dead", 0.57}, // (11-3)/(11+3)
+		{"the.dark.knight.rises.again", "The Dark Knight", 0.33}, // (10-5)/(10+5)
+		{"star.wars.the.last.jedi", "Star Wars", 0.33},           // (8-4)/(8+4)
+		{"it.follows", "It", 0},
+	}
+
+	for _, test := range tests {
+		score := MatchTitle(test.name, test.title)
+		if score < test.score-0.01 || score > test.score+0.01 {
+			t.Errorf("MatchTitle(%q, %q) = %.2f, expected %.2f", test.name, test.title, score, test.score)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package com
+
+import (
+	"regexp"
+	"strconv"
+	"time"
+)
+
+const (
+	minYear       = 1900
+	maxYearOffset = 1 // years after this year, for upcoming movies
+	yearTolerance = 1 // years, release year differs in countries
+)
+
+// like 2017 in It.2017.1080p, but not 1920 in 1920x1080
+var yearRegexp = regexp.MustCompile(`(?:^|[^0-9])((?:19|20)\d\d)(?:[^0-9x]|$)`)
+
+func isValidYear(year int) bool {
+	return year >= minYear && year <= time.Now().Year()+maxYearOffset
+}
+
+// getYears is all years in @name, separators between years are shared, like 2012.2009.
+func getYears(name string) []string {
+	var years []string
+	for start := 0; start < len(name); {
+		loc := yearRegexp.FindStringSubmatchIndex(name[start:])
+		if loc == nil {
+			break
+		}
+
+		years = append(years, name[start+loc[2]:start+loc[3]])
+		start += loc[3]
+	}
+
+	return years
+}
+
+// GetYear is getting release year from name, the last one is taken since title might be year like 2012.
+// 0 if unknown.
+func GetYear(name string) int {
+	year := 0
+	for _, s := range getYears(name) {
+		if v, _ := strconv.Atoi(s); isValidYear(v) {
+			year = v
+		}
+	}
+
+	return year
+}
+
+// getYearWithoutTitle is same as GetYear, but years which are words of @title are ignored, like 1917 for movie 1917.
+// @name, @title: lower case
+func getYearWithoutTitle(name string, title string) int {
+	titleWords := make(map[string]bool)
+	for _, word := range Split2Keywords(title) {
+		titleWords[word] = true
+	}
+
+	year := 0
+	for _, s := range getYears(name) {
+		if v, _ := strconv.Atoi(s); isValidYear(v) && !titleWords[s] {
+			year = v
+		}
+	}
+
+	return year
+}
+
+// isYearMatched is true if any year is unknown or they are close.
+func isYearMatched(year1, year2 int) bool {
+	if year1 == 0 || year2 == 0 {
+		return true
+	}
+
+	diff := year1 - year2
+	return diff >= -yearTolerance && diff <= yearTolerance
+}
+
+// sortItemsByYear is moving items of @year to front, others keep order.
+func sortItemsByYear(items []*Item, year int) []*Item {
+	if year == 0 {
+		return items
+	}
+
+	var newItems, otherItems []*Item
+	for _, item := range items {
+		if item.Year == year {
+			newItems = append(newItems, item)
+		} else {
+			otherItems = append(otherItems, item)
+		}
+	}
+
+	return append(newItems, otherItems...)
+}
@@ -0,0 +1,77 @@
+package com
+
+import "testing"
+
+func TestGetYear(t *testing.T) {
+	tests := []struct {
+		name string
+		want int
+	}{
+		{"it.2017.1080p.bluray.mkv", 2017},
+		{"inception.1080p.1920x1080.mkv", 0},
+		{"2012.2009.1080p.bluray.mkv", 2009}, // the last one
+		{"1917.1080p.bluray.x264.mkv", 1917},
+		{"movie.1899.mkv", 0},
+		{"movie.2999.mkv", 0},
+	}
+
+	for _, test := range tests {
+		if year := GetYear(test.name); year != test.want {
+			t.Errorf("GetYear(%s) = %d, want %d", test.name, year, test.want)
+		}
+	}
+}
+
+func TestGetYearWithoutTitle(t *testing.T) {
+	tests := []struct {
+		name, title string
+		want        int
+	}{
+		{"1917.1080p.bluray.x264.mkv", "1917", 0},
+		{"1917.2019.1080p.bluray.x264.mkv", "1917", 2019},
+		{"2012.1080p.bluray.mkv", "2012", 0},
+		{"blade.runner.2049.2017.1080p.mkv", "blade runner 2049", 2017},
+		{"it.2017.1080p.mkv", "it", 2017},
+	}
+
+	for _, test := range tests {
+		if year := getYearWithoutTitle(test.name, test.title); year != test.want {
+			t.Errorf("getYearWithoutTitle(%s, %s) = %d, want %d", test.name, test.title, year, test.want)
+		}
+	}
+}
+
+func TestToFileInfoTitleYear(t *testing.T) {
+	items := []*Item{
+		{Type: Movie, OrgName: "1917", ChName: "1917", Year: 2019},
+		{Type: Movie, OrgName: "2012", ChName: "2012", Year: 2009},
+		{Type: Movie, OrgName: "It", ChName: "小丑回魂", Year: 2017},
+	}
+
+	tests := []struct {
+		name    string
+		orgName string // empty if not matched
+		year    int
+	}{
+		{"1917.1080p.BluRay.x264.mkv", "1917", 2019},
+		{"1917.2019.1080p.BluRay.x264.mkv", "1917", 2019},
+		{"2012.1080p.BluRay.x264.mkv", "2012", 2009},
+		{"2012.2009.1080p.BluRay.x264.mkv", "2012", 2009},
+		{"2012.1990.1080p.BluRay.x264.mkv", "", 0}, // another movie of same name
+		{"It.1990.720p.mkv", "", 0},
+	}
+
+	for _, test := range tests {
+		fileInfo := ToFileInfo(test.name, items)
+		if test.orgName == "" {
+			if fileInfo != nil {
+				t.Errorf("ToFileInfo(%s) = %s, want nil", test.name, fileInfo.OrgName)
+			}
+			continue
+		}
+
+		if fileInfo == nil || fileInfo.OrgName != test.orgName || fileInfo.Year != test.year {
+			t.Errorf("ToFileInfo(%s) = %+v, want %s %d", test.name, fileInfo, test.orgName, test.year)
+		}
+	}
+}
@@ -2,15 +2,17 @@ package door
 
 import (
 	"hahajing/com"
+	"os"
 	"sync"
 	"time"
 )
 
 const (
 	keywordManagerUpdateCheckTimer = 10 // minute
-	keywordUpdateTimer             = 1  // second
+	keywordUpdateTimer             = 2  // second, within DouBan limit
 
-	keywordManagerUpdateHour = 1 // 几点开始更新
+	keywordManagerUpdateHour = 1  // 几点开始更新
+	keywordFreshTime         = 12 // hour, entries fetched later than it are not updated
 
 	keywordCheckReqChSize = 1000
 )
@@ -30,12 +32,17 @@ type KeywordCheckRes struct {
 
 // Door x
 type Door struct {
-	douBan     DouBan
-	douBanLock sync.Mutex
+	douBan  DouBan
+	mtime   MTime
+	localDB LocalDB
+	tmdb    TMDB
 
-	mtime MTime
+	providers    []*providerEntry // in priority order
+	providerLock sync.Mutex
 
-	guard Guard
+	// Parallel is querying all providers at the same time and merging their items,
+	// otherwise providers are queried in priority order until items are found.
+	Parallel bool
 
 	keywordManager    *com.KeywordManager
 	KeywordCheckReqCh chan *KeywordCheckReq
@@ -50,38 +57,48 @@ func (d *Door) Start(keywordManager *com.KeywordManager) {
 		com.HhjLog.Panic("DouBan start failed!")
 	}
 
+	// bad files are logged and skipped
+	nbr, _ := d.localDB.load(GetLocalDBPath())
+	if nbr > 0 {
+		com.HhjLog.Noticef("Local title database loaded %d items", nbr)
+		d.Register(&d.localDB, localDBPriority)
+	}
+
+	tmdbConfig, err := LoadTMDBConfig(GetTMDBConfigFileName())
+	if err != nil && !os.IsNotExist(err) {
+		com.HhjLog.Errorf("Load TMDB config failed: %s", err)
+	}
+	if tmdbConfig != nil && tmdbConfig.APIKey != "" {
+		d.tmdb.start(tmdbConfig)
+		d.Register(&d.tmdb, tmdbPriority)
+	}
+
+	d.Register(&d.douBan, douBanPriority)
+	d.Register(&d.mtime, mtimePriority)
+
 	go d.processRoutine()
 }
 
-func (d *Door) keywordCheckReqRoutine(req *KeywordCheckReq, code int) {
-	keywords := req.MyKeyword.SearchKeywords
-
-	var items []*com.Item
-	switch code {
-	case douBanCode:
-		d.douBanLock.Lock()
-		items = d.douBan.search(keywords, 1)
-		d.douBanLock.Unlock()
-	case mtimeCode:
-		items = d.mtime.search(keywords)
+func (d *Door) keywordCheckReqRoutine(req *KeywordCheckReq) {
+	items, err := d.search(req.MyKeyword.SearchKeywords)
+	switch err {
+	case errProvidersBusy:
+		req.ResCh <- &KeywordCheckRes{Items: nil, ErrorStr: "系统忙，请等会儿重试！"}
+		return
+	case errProvidersFailed:
+		req.ResCh <- &KeywordCheckRes{Items: nil, ErrorStr: "查询片名失败，请等会儿重试！"}
+		return
 	}
 
 	// firstly, store to keyword manager
-	d.keywordManager.Set(keywords, items)
+	d.keywordManager.Set(req.MyKeyword.SearchKeywords, items)
 
 	items = com.FilterItems(items, req.MyKeyword)
 	req.ResCh <- &KeywordCheckRes{Items: items}
 }
 
 func (d *Door) processKeywordCheckReq(req *KeywordCheckReq) {
-	t := time.Now().Unix()
-	code, ok := d.guard.add(t)
-	if !ok {
-		req.ResCh <- &KeywordCheckRes{Items: nil, ErrorStr: "系统忙，请等会儿重试！"}
-		return
-	}
-
-	go d.keywordCheckReqRoutine(req, code)
+	go d.keywordCheckReqRoutine(req)
 }
 
 func (d *Door) processRoutine() {
@@ -122,15 +139,13 @@ func (d *Door) updateKeywordManager(finishCh chan bool) {
 }
 
 func (d *Door) walkKeywordManager() {
-	keyStrs := d.keywordManager.GetKeyStrs()
+	// the stalest first, entries refreshed by user searches recently are skipped
+	tBefore := time.Now().Add(-keywordFreshTime * time.Hour).Unix()
+	keyStrs := d.keywordManager.GetStaleKeyStrs(tBefore)
 	for _, keyStr := range keyStrs {
-		// sync from DouBan
+		// sync from providers
 		keywords := com.Split2Keywords(keyStr)
-
-		d.douBanLock.Lock()
-		items := d.douBan.search(keywords, 1)
-		d.douBanLock.Unlock()
-
+		items, _ := d.search(keywords)
 		d.keywordManager.Set(keywords, items)
 
 		time.Sleep(keywordUpdateTimer * time.Second)
@@ -1,6 +1,8 @@
 package door
 
 import (
+	"context"
+	"errors"
 	"hahajing/com"
 	"net/http"
 	"net/http/cookiejar"
@@ -12,12 +14,45 @@ import (
 
 const maxDouBanTryNbr = 3
 
-// DouBan is crawler. Cocurrent isn't supported.
+// limit of DouBan requests per time interval
+const (
+	douBanReqLimit     = 40
+	douBanReqLimitTime = 60 // second
+)
+
+// DouBan is crawler. Cocurrent isn't supported, so Search is serialized.
 type DouBan struct {
 	client *http.Client
+	lock   chan bool // as mutex, waiting it can be cancelled
+}
+
+// Name x
+func (db *DouBan) Name() string {
+	return "DouBan"
+}
+
+// Limit x
+func (db *DouBan) Limit() (int, int64) {
+	return douBanReqLimit, douBanReqLimitTime
+}
+
+// Search x
+func (db *DouBan) Search(ctx context.Context, keywords []string) ([]*com.Item, error) {
+	select {
+	case db.lock <- true:
+		defer func() { <-db.lock }()
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	return db.search(ctx, keywords, 1)
 }
 
 func (db *DouBan) start() bool {
+	if db.lock == nil {
+		db.lock = make(chan bool, 1)
+	}
+
 	// Get cookie firstly
 	jar, _ := cookiejar.New(nil)
 	db.client = &http.Client{Jar: jar}
@@ -35,31 +70,36 @@ func (db *DouBan) start() bool {
 	return true
 }
 
-func (db *DouBan) search(keywords []string, level int) []*com.Item {
+func (db *DouBan) search(ctx context.Context, keywords []string, level int) ([]*com.Item, error) {
 	params := "q=" + url.QueryEscape(strings.Join(keywords, " "))
 	url := "https://www.douban.com/search?cat=1002&" + params
 	req, _ := http.NewRequest("GET", url, nil)
 	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 6.1; WOW64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/62.0.3202.94 Safari/537.36")
 
-	res, err := db.client.Do(req)
+	res, err := db.client.Do(req.WithContext(ctx))
 	if err != nil || res.StatusCode != 200 {
+		if err == nil {
+			res.Body.Close()
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
 		level++
 		if level > maxDouBanTryNbr {
-			com.HhjLog.Warning("Reach max DouBan retries!")
-			return nil
+			return nil, errors.New("reach max DouBan retries")
 		}
 
 		// new session
 		if !db.start() {
-			com.HhjLog.Warning("Start DouBan failed during search!")
-			return nil
+			return nil, errors.New("start DouBan failed during search")
 		}
 
 		// search it again
-		return db.search(keywords, level)
+		return db.search(ctx, keywords, level)
 	}
 
-	return db.getItems(res)
+	return db.getItems(res), nil
 }
 
 func (db *DouBan) getItems(res *http.Response) []*com.Item {
@@ -111,10 +151,11 @@ func (db *DouBan) getItems(res *http.Response) []*com.Item {
 			}
 		}
 
-		// original name
+		// original name, like 原名:Inception / 克里斯托弗·诺兰 / 莱昂纳多·迪卡普里奥 / 2010
 		sTemp = s.Find(".subject-cast")
 		text = sTemp.Text()
 		texts := strings.Split(text, " /")
+		year := com.GetYear(texts[len(texts)-1])
 		text = texts[0]
 		orgName := string([]rune(text)[3:])
 		orgName = strings.TrimSpace(orgName)
@@ -127,7 +168,8 @@ func (db *DouBan) getItems(res *http.Response) []*com.Item {
 		}
 
 		// new item
-		item := com.Item{Type: byType, OrgName: orgName, ChName: chName}
+		posterURL, _ := s.Find(".pic img").Attr("src")
+		item := com.Item{Type: byType, OrgName: orgName, ChName: chName, Year: year, PosterURL: posterURL}
 
 		// add different item
 		for _, pItem := range items {
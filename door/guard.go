@@ -1,36 +1,27 @@
 package door
 
-const (
-	douBanCode   = 0
-	mtimeCode    = 1
-	guardCodeNbr = 2
-)
-
-const codeRound = 6 // one in @codeRound will select DouBan
-
-// limits of request per time interval for each site code
-var doorReqLimits = []int{40, 200}
-var doorReqLimitTimes = []int64{60, 60} // second
-
-// Guard x
+// Guard is limiting requests per time interval for one provider.
 type Guard struct {
-	reqs  [guardCodeNbr][]int64 // [time]
-	round int
+	limit     int     // 0 is unlimited
+	limitTime int64   // second
+	reqs      []int64 // [time]
 }
 
-func (g *Guard) canPass(t int64, code int) (int, bool) {
-	times := g.reqs[code]
-	limit := doorReqLimits[code]
-	limitTime := doorReqLimitTimes[code]
+func (g *Guard) canPass(t int64) (int, bool) {
+	if g.limit == 0 {
+		return -1, true
+	}
+
+	times := g.reqs
 	count := 1 // assume this one is added.
 	i := len(times) - 1
 	for ; i >= 0; i-- {
-		if t-times[i] > limitTime {
+		if t-times[i] > g.limitTime {
 			break
 		}
 
 		count++
-		if count > limit {
+		if count > g.limit {
 			return 0, false
 		}
 	}
@@ -38,33 +29,23 @@ func (g *Guard) canPass(t int64, code int) (int, bool) {
 	return i, true // -1: empty
 }
 
-func (g *Guard) add(t int64) (int, bool) {
-	// get round
-	rounds := []int{douBanCode, mtimeCode}
-	g.round = g.round % codeRound
-	if g.round != 0 {
-		rounds = []int{mtimeCode, douBanCode}
+func (g *Guard) add(t int64) bool {
+	i, pass := g.canPass(t)
+	if !pass {
+		return false
 	}
 
-	g.round++
-
-	// try one by one
-	for _, code := range rounds {
-		i, pass := g.canPass(t, code)
-		if !pass {
-			continue
-		}
-
-		times := append(g.reqs[code], t)
+	if g.limit == 0 {
+		return true
+	}
 
-		// cut, we don't need more
-		if i < 0 {
-			i = 0
-		}
-		g.reqs[code] = times[i:]
+	times := append(g.reqs, t)
 
-		return code, true
+	// cut, we don't need more
+	if i < 0 {
+		i = 0
 	}
+	g.reqs = times[i:]
 
-	return -1, false
+	return true
 }
@@ -0,0 +1,356 @@
+package door
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"hahajing/com"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const maxLocalDBItems = 20
+
+// how names match keywords, only whole name is answered, others are left to other providers
+const (
+	localMatchNone  = iota
+	localMatchPart  // keywords are part of name, like dead for The Walking Dead
+	localMatchWhole // keywords are all words of name in any order, like walking dead for The Walking Dead
+	localMatchExact // keywords are name, like 琅琊榜
+)
+
+// rank of title types, smaller is better, TV movies and mini series are less searched
+var localTypeRanks = map[string]int{"movie": 0, "tvSeries": 0, "tv": 0, "tvMiniSeries": 1, "tvMovie": 2}
+
+// GetLocalDBPath is directory of local title database files, *.tsv and *.json.
+func GetLocalDBPath() string {
+	return com.GetConfigPath() + "/config/door/titles"
+}
+
+// localTitle is one title in JSON file, also used for TSV rows.
+type localTitle struct {
+	Type         string   // movie, tvMovie, tvSeries, tv or tvMiniSeries, like IMDb titleType
+	OrgName      string   // original name
+	ChName       string   // Chinese name
+	OtherChNames []string // other Chinese names and aliases
+	Year         int      // release year, 0 if unknown
+	Seasons      int      // number of seasons for TV, 0 if unknown
+}
+
+// TSV column names, the first row is header. Names of IMDb title.basics.tsv are supported too.
+var localDBColumns = map[string][]string{
+	"type":         {"type", "titleType"},
+	"orgName":      {"orgName", "originalTitle", "primaryTitle"},
+	"chName":       {"chName"},
+	"otherChNames": {"otherChNames", "aliases"}, // separated by |
+	"year":         {"year", "startYear"},
+	"seasons":      {"seasons"},
+}
+
+// LocalDB is offline title database in memory, indexed by keywords.
+// It is read only after loaded, so searching concurrently is supported.
+type LocalDB struct {
+	items     []*com.Item
+	typeRanks []int
+
+	// key is lower-case word of names, or Chinese character since there is no space between Chinese words.
+	indexMap map[string][]int
+	names    [][]string // normalized names of each item for matching, original, Chinese and other names
+}
+
+func (db *LocalDB) newItem(title *localTitle) *com.Item {
+	var byType byte
+	switch title.Type {
+	case "movie", "tvMovie":
+		byType = com.Movie
+	case "tvSeries", "tv":
+		byType = com.SeasonTV
+		if title.Seasons == 1 {
+			byType = com.NoSeasonTV
+		}
+	case "tvMiniSeries":
+		byType = com.NoSeasonTV
+	default: // episodes, shorts, games and so on
+		return nil
+	}
+
+	if title.OrgName == "" {
+		title.OrgName = title.ChName
+	}
+	if title.OrgName == "" {
+		return nil
+	}
+	if title.ChName == "" { // like titles from IMDb
+		title.ChName = title.OrgName
+	}
+
+	return &com.Item{Type: byType, OrgName: title.OrgName, ChName: title.ChName,
+		OtherChName: strings.Join(title.OtherChNames, " / "), Year: title.Year, Seasons: title.Seasons}
+}
+
+func (db *LocalDB) add(title *localTitle) {
+	item := db.newItem(title)
+	if item == nil {
+		return
+	}
+
+	i := len(db.items)
+	db.items = append(db.items, item)
+	db.typeRanks = append(db.typeRanks, localTypeRanks[title.Type])
+
+	var names []string
+	for _, name := range append([]string{item.OrgName, item.ChName}, title.OtherChNames...) {
+		names = append(names, com.NormalizeChinese(name))
+	}
+	db.names = append(db.names, names)
+
+	keys := make(map[string]bool)
+	for _, name := range names {
+		for _, key := range com.Split2Keywords(name) {
+			keys[key] = true
+			for _, c := range key {
+				if com.IsChinese(c) {
+					keys[string(c)] = true
+				}
+			}
+		}
+	}
+
+	for key := range keys {
+		db.indexMap[key] = append(db.indexMap[key], i)
+	}
+}
+
+func (db *LocalDB) loadJSON(fileName string) error {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var titles []*localTitle
+	if err := json.NewDecoder(f).Decode(&titles); err != nil {
+		return err
+	}
+
+	for _, title := range titles {
+		db.add(title)
+	}
+
+	return nil
+}
+
+func (db *LocalDB) loadTSV(fileName string) error {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1024*1024)
+
+	// header
+	columns := make(map[string]int)
+	if scanner.Scan() {
+		header := strings.Split(scanner.Text(), "\t")
+		for name, aliases := range localDBColumns {
+			for _, alias := range aliases {
+				if _, ok := columns[name]; ok {
+					break
+				}
+				for i, column := range header {
+					if column == alias {
+						columns[name] = i
+						break
+					}
+				}
+			}
+		}
+	}
+
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), "\t")
+		get := func(name string) string {
+			i, ok := columns[name]
+			if !ok || i >= len(fields) || fields[i] == `\N` { // \N is null in IMDb
+				return ""
+			}
+			return strings.TrimSpace(fields[i])
+		}
+
+		title := localTitle{Type: get("type"), OrgName: get("orgName"), ChName: get("chName")}
+		title.Year, _ = strconv.Atoi(get("year"))
+		title.Seasons, _ = strconv.Atoi(get("seasons"))
+		for _, name := range strings.Split(get("otherChNames"), "|") {
+			if name = strings.TrimSpace(name); name != "" {
+				title.OtherChNames = append(title.OtherChNames, name)
+			}
+		}
+
+		db.add(&title)
+	}
+
+	return scanner.Err()
+}
+
+// load is loading all *.tsv and *.json files in @path, returning number of items and the first error.
+// Bad file is skipped, others are still loaded.
+func (db *LocalDB) load(path string) (int, error) {
+	db.items = nil
+	db.typeRanks = nil
+	db.names = nil
+	db.indexMap = make(map[string][]int)
+
+	fileNames, err := filepath.Glob(path + "/*")
+	if err != nil {
+		return 0, err
+	}
+
+	var firstErr error
+	for _, fileName := range fileNames {
+		switch strings.ToLower(filepath.Ext(fileName)) {
+		case ".tsv":
+			err = db.loadTSV(fileName)
+		case ".json":
+			err = db.loadJSON(fileName)
+		default:
+			continue
+		}
+
+		if err != nil {
+			com.HhjLog.Errorf("Load local title database %s failed: %s", fileName, err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+
+	return len(db.items), firstErr
+}
+
+// Name x
+func (db *LocalDB) Name() string {
+	return "LocalDB"
+}
+
+// Limit is unlimited since there is no network.
+func (db *LocalDB) Limit() (int, int64) {
+	return 0, 0
+}
+
+// Search x
+func (db *LocalDB) Search(ctx context.Context, keywords []string) ([]*com.Item, error) {
+	return db.search(keywords), nil
+}
+
+// getNameMatch is how @name matches @keys, all are normalized.
+func getNameMatch(name string, keys []string) int {
+	for _, key := range keys {
+		if strings.Index(name, key) == -1 {
+			return localMatchNone
+		}
+	}
+
+	// Chinese name has no spaces
+	if strings.Replace(name, " ", "", -1) == strings.Join(keys, "") {
+		return localMatchExact
+	}
+
+	nameTokens := com.Split2Keywords(name)
+	if strings.Join(nameTokens, " ") == strings.Join(keys, " ") {
+		return localMatchExact
+	}
+
+	// same words without stop words, in any order
+	words := make(map[string]bool)
+	for _, token := range nameTokens {
+		if !com.IsStopWord(token) {
+			words[token] = true
+		}
+	}
+	for _, key := range keys {
+		if !com.IsStopWord(key) && !words[key] {
+			return localMatchPart
+		}
+		delete(words, key)
+	}
+	if len(words) == 0 {
+		return localMatchWhole
+	}
+
+	return localMatchPart
+}
+
+// search is getting items whose names are @keywords, the best matched first, without network.
+// Items only partly matched are not returned, so that other providers can be asked.
+func (db *LocalDB) search(keywords []string) []*com.Item {
+	if len(db.items) == 0 || len(keywords) == 0 {
+		return nil
+	}
+
+	// candidates from the shortest index
+	var keys []string
+	var candidates []int
+	for _, keyword := range keywords {
+		key := com.NormalizeChinese(keyword)
+		keys = append(keys, key)
+
+		var indexes []int
+		if com.IsChinese([]rune(key)[0]) {
+			indexes = db.indexMap[string([]rune(key)[0])]
+		} else {
+			indexes = db.indexMap[key]
+		}
+
+		if len(indexes) == 0 {
+			return nil
+		}
+		if candidates == nil || len(indexes) < len(candidates) {
+			candidates = indexes
+		}
+	}
+
+	type localMatch struct {
+		i     int
+		match int
+	}
+	var matches []localMatch
+	for _, i := range candidates {
+		match := localMatchNone
+		for _, name := range db.names[i] {
+			if m := getNameMatch(name, keys); m > match {
+				match = m
+			}
+		}
+
+		if match >= localMatchWhole {
+			matches = append(matches, localMatch{i: i, match: match})
+		}
+	}
+
+	// exact first, then better type, then newer
+	sort.SliceStable(matches, func(i, j int) bool {
+		m1, m2 := matches[i], matches[j]
+		if m1.match != m2.match {
+			return m1.match > m2.match
+		}
+		if db.typeRanks[m1.i] != db.typeRanks[m2.i] {
+			return db.typeRanks[m1.i] < db.typeRanks[m2.i]
+		}
+		return db.items[m1.i].Year > db.items[m2.i].Year
+	})
+
+	var items []*com.Item
+	for _, m := range matches {
+		items = append(items, db.items[m.i])
+		if len(items) == maxLocalDBItems {
+			break
+		}
+	}
+
+	return items
+}
@@ -0,0 +1,67 @@
+package door
+
+import (
+	"hahajing/com"
+	"testing"
+)
+
+func TestLocalDBLoad(t *testing.T) {
+	var db LocalDB
+
+	// broken.json is the first file, later ones are still loaded
+	nbr, err := db.load("testdata/localdb")
+	if err == nil {
+		t.Error("load of broken.json should fail")
+	}
+
+	// TV episode is skipped
+	if nbr != 7 {
+		t.Errorf("load got %d items, want 7", nbr)
+	}
+}
+
+func TestLocalDBSearch(t *testing.T) {
+	var db LocalDB
+	db.load("testdata/localdb")
+
+	tests := []struct {
+		keywords []string
+		names    []string // ChName of items in order
+	}{
+		// series before TV movie of the same name
+		{[]string{"the", "walking", "dead"}, []string{"The Walking Dead", "The Walking Dead"}},
+		{[]string{"walking", "dead"}, []string{"The Walking Dead", "The Walking Dead"}},
+		{[]string{"inception"}, []string{"Inception"}},
+		{[]string{"琅琊榜"}, []string{"琅琊榜"}},
+		{[]string{"瑯琊榜"}, []string{"琅琊榜"}},
+		{[]string{"nirvana", "in", "fire"}, []string{"琅琊榜"}},
+		{[]string{"捉妖记"}, []string{"捉妖记"}},
+		{[]string{"monster", "hunt", "2"}, []string{"捉妖记2"}},
+
+		// only part of names, left to other providers
+		{[]string{"dead"}, nil},
+		{[]string{"琅琊"}, nil},
+		{[]string{"monster"}, nil},
+		{[]string{"dead", "man"}, nil},
+		{[]string{"unknown"}, nil},
+	}
+
+	for _, test := range tests {
+		items := db.search(test.keywords)
+		if len(items) != len(test.names) {
+			t.Errorf("search(%v) got %d items, want %d", test.keywords, len(items), len(test.names))
+			continue
+		}
+
+		for i, item := range items {
+			if item.ChName != test.names[i] {
+				t.Errorf("search(%v)[%d] is %s, want %s", test.keywords, i, item.ChName, test.names[i])
+			}
+		}
+	}
+
+	items := db.search([]string{"walking", "dead"})
+	if len(items) == 2 && (items[0].Type != com.SeasonTV || items[0].Year != 2010 || items[1].Type != com.Movie) {
+		t.Errorf("search(walking dead) got %+v, %+v, series should be first", items[0], items[1])
+	}
+}
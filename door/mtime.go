@@ -1,6 +1,7 @@
 package door
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"hahajing/com"
@@ -12,10 +13,31 @@ import (
 
 const mtimeURL = "http://service.channel.mtime.com/Search.api?Ajax_CallBack=true&Ajax_CallBackType=Mtime.Channel.Services&Ajax_CallBackMethod=GetSearchResult&Ajax_CallBackArgument0=%s&Ajax_CallBackArgument1=1&Ajax_CallBackArgument2=290&Ajax_CallBackArgument3=0&Ajax_CallBackArgument4=1"
 
+// limit of MTime requests per time interval
+const (
+	mtimeReqLimit     = 200
+	mtimeReqLimitTime = 60 // second
+)
+
 // MTime x
 type MTime struct {
 }
 
+// Name x
+func (m *MTime) Name() string {
+	return "MTime"
+}
+
+// Limit x
+func (m *MTime) Limit() (int, int64) {
+	return mtimeReqLimit, mtimeReqLimitTime
+}
+
+// Search x
+func (m *MTime) Search(ctx context.Context, keywords []string) ([]*com.Item, error) {
+	return m.search(ctx, keywords)
+}
+
 func (m *MTime) newItem(title, otherTitle string, mediaLength int) *com.Item {
 	i := strings.Index(title, " ")
 	if i == -1 {
@@ -33,6 +55,9 @@ func (m *MTime) newItem(title, otherTitle string, mediaLength int) *com.Item {
 		orgName = title[i+1 : j]
 	}
 
+	// like 盗梦空间 Inception (2010)
+	year := com.GetYear(title[j:])
+
 	// other Chinese name
 	otherChName := ""
 	i = strings.Index(otherTitle, "更多名：")
@@ -45,7 +70,7 @@ func (m *MTime) newItem(title, otherTitle string, mediaLength int) *com.Item {
 		byType = com.Movie
 	}
 
-	return &com.Item{Type: byType, OrgName: orgName, ChName: chName, OtherChName: otherChName}
+	return &com.Item{Type: byType, OrgName: orgName, ChName: chName, OtherChName: otherChName, Year: year}
 }
 
 func (m *MTime) getItems(data []byte) []*com.Item {
@@ -106,24 +131,26 @@ func (m *MTime) getItems(data []byte) []*com.Item {
 	return items
 }
 
-func (m *MTime) search(keywords []string) []*com.Item {
+func (m *MTime) search(ctx context.Context, keywords []string) ([]*com.Item, error) {
 	client := &http.Client{}
 
 	params := url.QueryEscape(strings.Join(keywords, " "))
 	url := fmt.Sprintf(mtimeURL, params)
-	res, err := client.Get(url)
-	if err != nil || res.StatusCode != 200 {
-		return nil
+	req, _ := http.NewRequest("GET", url, nil)
+	res, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != 200 {
+		return nil, fmt.Errorf("MTime status %d", res.StatusCode)
 	}
 
 	data, err := ioutil.ReadAll(res.Body)
 	if err != nil {
-		return nil
+		return nil, err
 	}
 
-	items := m.getItems(data)
-
-	res.Body.Close()
-
-	return items
+	return m.getItems(data), nil
 }
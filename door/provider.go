@@ -0,0 +1,209 @@
+package door
+
+import (
+	"context"
+	"errors"
+	"hahajing/com"
+	"sort"
+	"sync"
+	"time"
+)
+
+const providerSearchTime = 4 // second, less than keyword check waiting time of web
+
+var (
+	errProvidersBusy   = errors.New("all providers reach their limits")
+	errProvidersFailed = errors.New("all providers failed")
+)
+
+// priorities of built-in providers, smaller is queried firstly
+const (
+	localDBPriority = 0
+	tmdbPriority    = 5
+	douBanPriority  = 10
+	mtimePriority   = 20
+)
+
+// Provider is source of items for keywords, like DouBan. Search must be safe for concurrent use.
+type Provider interface {
+	Name() string
+
+	// Search is getting items of @keywords, nil without error if not found.
+	Search(ctx context.Context, keywords []string) ([]*com.Item, error)
+
+	// Limit is max requests per time interval in second, 0 requests is unlimited.
+	Limit() (int, int64)
+}
+
+type providerEntry struct {
+	provider Provider
+	priority int
+	guard    Guard
+}
+
+// Register is adding provider of @priority, smaller is queried firstly. It should be called before Start.
+func (d *Door) Register(provider Provider, priority int) {
+	limit, limitTime := provider.Limit()
+	entry := providerEntry{provider: provider, priority: priority, guard: Guard{limit: limit, limitTime: limitTime}}
+
+	d.providerLock.Lock()
+	d.providers = append(d.providers, &entry)
+	sort.SliceStable(d.providers, func(i, j int) bool { return d.providers[i].priority < d.providers[j].priority })
+	d.providerLock.Unlock()
+}
+
+// pass is true if @entry doesn't reach its limit, and the request is counted.
+func (d *Door) pass(entry *providerEntry) bool {
+	d.providerLock.Lock()
+	defer d.providerLock.Unlock()
+
+	return entry.guard.add(time.Now().Unix())
+}
+
+func (d *Door) getProviders() []*providerEntry {
+	d.providerLock.Lock()
+	defer d.providerLock.Unlock()
+
+	return append([]*providerEntry(nil), d.providers...)
+}
+
+// search is getting items from providers, errProvidersBusy if all providers reach their limits,
+// or errProvidersFailed if all queried providers failed.
+// In priority order, the next provider is tried if one fails or finds nothing, each has its share of remaining time.
+// Items without Chinese name, like ones of IMDb in local database, are not enough, later providers are tried too.
+// In parallel, items of all providers are merged.
+func (d *Door) search(keywords []string) ([]*com.Item, error) {
+	deadline := time.Now().Add(providerSearchTime * time.Second)
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+
+	providers := d.getProviders()
+	itemsList := make([][]*com.Item, len(providers))
+	errs := make([]error, len(providers))
+
+	var wg sync.WaitGroup
+	nbr := 0 // queried providers
+	for i, entry := range providers {
+		if !d.pass(entry) {
+			continue
+		}
+		nbr++
+
+		if !d.Parallel {
+			// slow provider can't use up time of later ones
+			timeout := time.Until(deadline) / time.Duration(len(providers)-i)
+			providerCtx, providerCancel := context.WithTimeout(ctx, timeout)
+			itemsList[i], errs[i] = d.searchProvider(providerCtx, entry, keywords)
+			providerCancel()
+
+			if hasChineseName(itemsList[i]) {
+				break
+			}
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, entry *providerEntry) {
+			itemsList[i], errs[i] = d.searchProvider(ctx, entry, keywords)
+			wg.Done()
+		}(i, entry)
+	}
+	wg.Wait()
+
+	if nbr == 0 {
+		return nil, errProvidersBusy
+	}
+
+	failedNbr := 0
+	for _, err := range errs {
+		if err != nil {
+			failedNbr++
+		}
+	}
+	if failedNbr == nbr {
+		return nil, errProvidersFailed
+	}
+
+	return mergeItems(itemsList), nil
+}
+
+func (d *Door) searchProvider(ctx context.Context, entry *providerEntry, keywords []string) ([]*com.Item, error) {
+	items, err := entry.provider.Search(ctx, keywords)
+	if err != nil {
+		com.HhjLog.Warningf("Search %s failed: %s", entry.provider.Name(), err)
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// hasChineseName is true if any item has Chinese name, which is needed for Chinese file names.
+func hasChineseName(items []*com.Item) bool {
+	for _, item := range items {
+		if com.HasChinese(item.ChName) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isSameTitle is true if items are same title from different providers, which may not know type or year.
+func isSameTitle(item1, item2 *com.Item) bool {
+	if com.NormalizeChinese(item1.OrgName) != com.NormalizeChinese(item2.OrgName) {
+		return false
+	}
+
+	if item1.Type != item2.Type && item1.Type != com.UnknownType && item2.Type != com.UnknownType {
+		return false
+	}
+
+	return item1.Year == item2.Year || item1.Year == 0 || item2.Year == 0
+}
+
+// mergeItems is merging items in priority order, same titles are one with unknown fields filled by later ones.
+// Chinese name is filled too if it's not Chinese.
+func mergeItems(itemsList [][]*com.Item) []*com.Item {
+	var items []*com.Item
+	for _, newItems := range itemsList {
+		for _, newItem := range newItems {
+			existing := false
+			for i, item := range items {
+				if !isSameTitle(item, newItem) {
+					continue
+				}
+
+				// copy since items may be shared by provider
+				merged := *item
+				if merged.Type == com.UnknownType {
+					merged.Type = newItem.Type
+				}
+				if merged.Year == 0 {
+					merged.Year = newItem.Year
+				}
+				if !com.HasChinese(merged.ChName) && com.HasChinese(newItem.ChName) { // like original name from IMDb
+					merged.ChName = newItem.ChName
+				}
+				if merged.OtherChName == "" {
+					merged.OtherChName = newItem.OtherChName
+				}
+				if merged.PosterURL == "" {
+					merged.PosterURL = newItem.PosterURL
+				}
+				if merged.Seasons == 0 {
+					merged.Seasons = newItem.Seasons
+				}
+				items[i] = &merged
+
+				existing = true
+				break
+			}
+
+			if !existing {
+				items = append(items, newItem)
+			}
+		}
+	}
+
+	return items
+}
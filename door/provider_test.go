@@ -0,0 +1,109 @@
+package door
+
+import (
+	"context"
+	"errors"
+	"hahajing/com"
+	"testing"
+)
+
+type fakeProvider struct {
+	name  string
+	items []*com.Item
+	err   error
+	limit int
+	slow  bool // blocking until timeout
+}
+
+func (p *fakeProvider) Name() string {
+	return p.name
+}
+
+func (p *fakeProvider) Limit() (int, int64) {
+	return p.limit, 60
+}
+
+func (p *fakeProvider) Search(ctx context.Context, keywords []string) ([]*com.Item, error) {
+	if p.slow {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	return p.items, p.err
+}
+
+func TestSearchFailover(t *testing.T) {
+	var d Door
+	d.Register(&fakeProvider{name: "mtime", items: []*com.Item{{Type: com.Movie, OrgName: "It", Year: 2017}}}, 20)
+	d.Register(&fakeProvider{name: "douban", slow: true}, 10)
+	d.Register(&fakeProvider{name: "error", err: errors.New("blocked")}, 0)
+
+	items, err := d.search([]string{"it"})
+	if err != nil || len(items) != 1 || items[0].OrgName != "It" {
+		t.Fatalf("search = %v, %v, expected item from the last provider", items, err)
+	}
+}
+
+func TestSearchErrors(t *testing.T) {
+	var d Door
+	d.Register(&fakeProvider{name: "a", err: errors.New("blocked"), limit: 1}, 0)
+	d.Register(&fakeProvider{name: "b", err: errors.New("changed"), limit: 1}, 1)
+
+	if _, err := d.search([]string{"it"}); err != errProvidersFailed {
+		t.Errorf("all failed: %v, expected %v", err, errProvidersFailed)
+	}
+
+	if _, err := d.search([]string{"it"}); err != errProvidersBusy {
+		t.Errorf("all busy: %v, expected %v", err, errProvidersBusy)
+	}
+
+	// nothing found isn't error
+	var e Door
+	e.Register(&fakeProvider{name: "a"}, 0)
+	e.Register(&fakeProvider{name: "b", err: errors.New("blocked")}, 1)
+	if items, err := e.search([]string{"it"}); items != nil || err != nil {
+		t.Errorf("nothing found: %v, %v", items, err)
+	}
+}
+
+func TestSearchParallel(t *testing.T) {
+	var d Door
+	d.Parallel = true
+	d.Register(&fakeProvider{name: "a", items: []*com.Item{{Type: com.UnknownType, OrgName: "It", ChName: "小丑回魂", Year: 2017}}}, 0)
+	d.Register(&fakeProvider{name: "b", items: []*com.Item{{Type: com.Movie, OrgName: "it", PosterURL: "p"}, {Type: com.Movie, OrgName: "Up"}}}, 1)
+	d.Register(&fakeProvider{name: "c", err: errors.New("blocked")}, 2)
+
+	items, err := d.search([]string{"it"})
+	if err != nil || len(items) != 2 {
+		t.Fatalf("search = %v, %v, expected 2 merged items", items, err)
+	}
+
+	if item := items[0]; item.Type != com.Movie || item.ChName != "小丑回魂" || item.Year != 2017 || item.PosterURL != "p" {
+		t.Errorf("merged item: %+v", *item)
+	}
+}
+
+func TestSearchWithoutChineseName(t *testing.T) {
+	var d Door
+	d.Register(&fakeProvider{name: "localdb", items: []*com.Item{{Type: com.Movie, OrgName: "Inception", ChName: "Inception", Year: 2010}}}, localDBPriority)
+	d.Register(&fakeProvider{name: "douban", items: []*com.Item{{Type: com.Movie, OrgName: "Inception", ChName: "盗梦空间", Year: 2010, PosterURL: "p"}}}, douBanPriority)
+	d.Register(&fakeProvider{name: "mtime", err: errors.New("not queried")}, mtimePriority)
+
+	// DouBan is asked since local item has no Chinese name, and it's enough
+	items, err := d.search([]string{"inception"})
+	if err != nil || len(items) != 1 || items[0].PosterURL != "p" || items[0].ChName != "盗梦空间" {
+		t.Fatalf("search = %v, %v, expected one item merged with DouBan", items, err)
+	}
+
+	// only local item is still found
+	var e Door
+	e.Register(&fakeProvider{name: "localdb", items: []*com.Item{{Type: com.Movie, OrgName: "Inception", ChName: "Inception"}}}, localDBPriority)
+	e.Register(&fakeProvider{name: "douban"}, douBanPriority)
+	if items, err := e.search([]string{"inception"}); err != nil || len(items) != 1 {
+		t.Errorf("search = %v, %v, expected local item", items, err)
+	}
+}
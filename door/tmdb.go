@@ -0,0 +1,245 @@
+package door
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hahajing/com"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	tmdbDefaultBaseURL  = "https://api.themoviedb.org/3"
+	tmdbDefaultImageURL = "https://image.tmdb.org/t/p/w92"
+	tmdbDefaultLanguage = "zh-CN"
+
+	maxTMDBResults = 5 // movies and TV of search result, each needs one more request for details
+)
+
+// limit of TMDB searches per time interval, each search is 1 + maxTMDBResults requests at most,
+// so that requests are within 40 per 10 seconds.
+const (
+	tmdbReqLimit     = 40 / (1 + maxTMDBResults)
+	tmdbReqLimitTime = 10 // second
+)
+
+// countries of alternative titles in Chinese, like zh-CN and zh-TW
+var tmdbChineseCountries = map[string]bool{"CN": true, "TW": true, "HK": true, "SG": true}
+
+// GetTMDBConfigFileName x
+func GetTMDBConfigFileName() string {
+	return com.GetConfigPath() + "/config/door/tmdb.json"
+}
+
+// TMDBConfig is config of TMDB provider, which isn't used without API key.
+type TMDBConfig struct {
+	APIKey   string
+	BaseURL  string // TMDB v3 API by default
+	ImageURL string // prefix of poster path
+	Language string // zh-CN by default
+}
+
+// LoadTMDBConfig x
+func LoadTMDBConfig(fileName string) (*TMDBConfig, error) {
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	var config TMDBConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+type tmdbSearchRes struct {
+	Results []struct {
+		ID        int    `json:"id"`
+		MediaType string `json:"media_type"` // movie, tv or person
+	} `json:"results"`
+}
+
+type tmdbAltTitle struct {
+	Country string `json:"iso_3166_1"`
+	Title   string `json:"title"`
+}
+
+// tmdbDetail is details of movie or TV, with alternative titles appended.
+type tmdbDetail struct {
+	Title         string `json:"title"` // movie
+	OriginalTitle string `json:"original_title"`
+	ReleaseDate   string `json:"release_date"`
+
+	Name            string `json:"name"` // TV
+	OriginalName    string `json:"original_name"`
+	FirstAirDate    string `json:"first_air_date"`
+	NumberOfSeasons int    `json:"number_of_seasons"`
+
+	PosterPath string `json:"poster_path"`
+
+	AlternativeTitles struct {
+		Titles  []tmdbAltTitle `json:"titles"`  // movie
+		Results []tmdbAltTitle `json:"results"` // TV
+	} `json:"alternative_titles"`
+}
+
+type tmdbError struct {
+	StatusCode    int    `json:"status_code"`
+	StatusMessage string `json:"status_message"`
+}
+
+// TMDB is client of TMDB v3 API.
+type TMDB struct {
+	config TMDBConfig
+	client *http.Client
+}
+
+func (t *TMDB) start(config *TMDBConfig) {
+	t.config = *config
+	if t.config.BaseURL == "" {
+		t.config.BaseURL = tmdbDefaultBaseURL
+	}
+	if t.config.ImageURL == "" {
+		t.config.ImageURL = tmdbDefaultImageURL
+	}
+	if t.config.Language == "" {
+		t.config.Language = tmdbDefaultLanguage
+	}
+
+	t.client = &http.Client{}
+}
+
+// Name x
+func (t *TMDB) Name() string {
+	return "TMDB"
+}
+
+// Limit x
+func (t *TMDB) Limit() (int, int64) {
+	return tmdbReqLimit, tmdbReqLimitTime
+}
+
+func (t *TMDB) getJSON(ctx context.Context, path string, params url.Values, v interface{}) error {
+	params.Set("api_key", t.config.APIKey)
+	params.Set("language", t.config.Language)
+
+	req, _ := http.NewRequest("GET", t.config.BaseURL+path+"?"+params.Encode(), nil)
+	res, err := t.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	data, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode != 200 {
+		var tmdbErr tmdbError
+		json.Unmarshal(data, &tmdbErr)
+		return fmt.Errorf("TMDB status %d: %s", res.StatusCode, tmdbErr.StatusMessage)
+	}
+
+	return json.Unmarshal(data, v)
+}
+
+func (t *TMDB) newItem(mediaType string, detail *tmdbDetail) *com.Item {
+	item := com.Item{Type: com.Movie, OrgName: detail.OriginalTitle, ChName: detail.Title}
+	date := detail.ReleaseDate
+	altTitles := detail.AlternativeTitles.Titles
+	if mediaType == "tv" {
+		item.Type = com.SeasonTV
+		if detail.NumberOfSeasons == 1 {
+			item.Type = com.NoSeasonTV
+		}
+		item.OrgName, item.ChName = detail.OriginalName, detail.Name
+		item.Seasons = detail.NumberOfSeasons
+		date = detail.FirstAirDate
+		altTitles = detail.AlternativeTitles.Results
+	}
+
+	if item.OrgName == "" {
+		return nil
+	}
+
+	// TMDB gives original name if there is no translation
+	if !com.HasChinese(item.ChName) {
+		item.ChName = item.OrgName
+	}
+
+	// like 2010-07-15
+	if len(date) >= 4 {
+		item.Year, _ = strconv.Atoi(date[:4])
+	}
+
+	if detail.PosterPath != "" {
+		item.PosterURL = t.config.ImageURL + detail.PosterPath
+	}
+
+	// other Chinese names, like 陰屍路 in Taiwan for 行尸走肉
+	var otherChNames []string
+	names := map[string]bool{item.ChName: true}
+	for _, altTitle := range altTitles {
+		if tmdbChineseCountries[altTitle.Country] && com.HasChinese(altTitle.Title) && !names[altTitle.Title] {
+			names[altTitle.Title] = true
+			otherChNames = append(otherChNames, altTitle.Title)
+		}
+	}
+	item.OtherChName = strings.Join(otherChNames, " / ")
+
+	return &item
+}
+
+// Search x
+func (t *TMDB) Search(ctx context.Context, keywords []string) ([]*com.Item, error) {
+	var res tmdbSearchRes
+	params := url.Values{"query": {strings.Join(keywords, " ")}, "include_adult": {"false"}}
+	if err := t.getJSON(ctx, "/search/multi", params, &res); err != nil {
+		return nil, err
+	}
+
+	var items []*com.Item
+	detailNbr := 0
+	for _, result := range res.Results {
+		if result.MediaType != "movie" && result.MediaType != "tv" {
+			continue
+		}
+
+		// one request for details of each
+		if detailNbr == maxTMDBResults {
+			break
+		}
+		detailNbr++
+
+		var detail tmdbDetail
+		path := fmt.Sprintf("/%s/%d", result.MediaType, result.ID)
+		params := url.Values{"append_to_response": {"alternative_titles"}}
+		if err := t.getJSON(ctx, path, params, &detail); err != nil {
+			// out of time, details got so far are still useful
+			if ctx.Err() != nil {
+				if items == nil {
+					return nil, ctx.Err()
+				}
+
+				com.HhjLog.Warningf("Get TMDB details of %v timed out, %d items got", keywords, len(items))
+				return items, nil
+			}
+
+			com.HhjLog.Warningf("Get TMDB details of %s failed: %s", path, err)
+			continue
+		}
+
+		if item := t.newItem(result.MediaType, &detail); item != nil {
+			items = append(items, item)
+		}
+	}
+
+	return items, nil
+}
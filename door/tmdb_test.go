@@ -0,0 +1,174 @@
+package door
+
+import (
+	"context"
+	"hahajing/com"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// newTMDBFakeServer is fake TMDB v3 API serving responses in testdata/tmdb, files are named by path,
+// like search_multi_walking_dead.json for /search/multi?query=walking dead and tv_1402.json for /tv/1402.
+func newTMDBFakeServer(t *testing.T) (*httptest.Server, *int32) {
+	var reqNbr int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&reqNbr, 1)
+		w.Header().Set("Content-Type", "application/json;charset=utf-8")
+
+		write := func(status int, fileName string) {
+			data, err := ioutil.ReadFile("testdata/tmdb/" + fileName + ".json")
+			if err != nil {
+				t.Error(err)
+			}
+			w.WriteHeader(status)
+			w.Write(data)
+		}
+
+		if r.URL.Query().Get("api_key") == "" {
+			write(http.StatusUnauthorized, "error_invalid_key")
+			return
+		}
+
+		fileName := strings.Replace(strings.Trim(r.URL.Path, "/"), "/", "_", -1)
+		if r.URL.Path == "/search/multi" {
+			fileName += "_" + strings.Replace(r.URL.Query().Get("query"), " ", "_", -1)
+		}
+
+		if _, err := ioutil.ReadFile("testdata/tmdb/" + fileName + ".json"); err != nil {
+			if r.URL.Path == "/search/multi" {
+				write(http.StatusOK, "search_multi_empty")
+			} else {
+				write(http.StatusNotFound, "error_not_found")
+			}
+			return
+		}
+
+		write(http.StatusOK, fileName)
+	}))
+
+	return server, &reqNbr
+}
+
+func newTestTMDB(t *testing.T, apiKey string) (*TMDB, *httptest.Server, *int32) {
+	server, reqNbr := newTMDBFakeServer(t)
+
+	var tmdb TMDB
+	tmdb.start(&TMDBConfig{APIKey: apiKey, BaseURL: server.URL, ImageURL: "https://image/w92"})
+
+	return &tmdb, server, reqNbr
+}
+
+func TestTMDBSearchTV(t *testing.T) {
+	tmdb, server, reqNbr := newTestTMDB(t, "key")
+	defer server.Close()
+
+	items, err := tmdb.Search(context.Background(), []string{"walking", "dead"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// person is skipped
+	if nbr := atomic.LoadInt32(reqNbr); len(items) != 2 || nbr != 3 {
+		t.Fatalf("%d items by %d requests, expected 2 items by 3 requests", len(items), nbr)
+	}
+
+	expected := com.Item{Type: com.SeasonTV, OrgName: "The Walking Dead", ChName: "行尸走肉", OtherChName: "陰屍路 / 行屍",
+		Year: 2010, PosterURL: "https://image/w92/walking_dead.jpg", Seasons: 11}
+	if *items[0] != expected {
+		t.Errorf("item: %+v, expected: %+v", *items[0], expected)
+	}
+
+	if items[1].OrgName != "Fear the Walking Dead" || items[1].Year != 2015 || items[1].Seasons != 8 || items[1].OtherChName != "驚嚇陰屍路" {
+		t.Errorf("item: %+v", *items[1])
+	}
+}
+
+func TestTMDBSearchMovie(t *testing.T) {
+	tmdb, server, _ := newTestTMDB(t, "key")
+	defer server.Close()
+
+	items, err := tmdb.Search(context.Background(), []string{"inception"})
+	if err != nil || len(items) != 1 {
+		t.Fatalf("search = %v, %v", items, err)
+	}
+
+	// same as Chinese name is not other name
+	expected := com.Item{Type: com.Movie, OrgName: "Inception", ChName: "盗梦空间", OtherChName: "全面啟動 / 潛行凶間",
+		Year: 2010, PosterURL: "https://image/w92/inception.jpg"}
+	if *items[0] != expected {
+		t.Errorf("item: %+v, expected: %+v", *items[0], expected)
+	}
+}
+
+func TestTMDBSearchNoTranslation(t *testing.T) {
+	tmdb, server, _ := newTestTMDB(t, "key")
+	defer server.Close()
+
+	items, err := tmdb.Search(context.Background(), []string{"chernobyl"})
+	if err != nil || len(items) != 1 {
+		t.Fatalf("search = %v, %v", items, err)
+	}
+
+	// one season is TV without season, Chinese name is from alternative titles only
+	expected := com.Item{Type: com.NoSeasonTV, OrgName: "Chernobyl", ChName: "Chernobyl", OtherChName: "切尔诺贝利",
+		Year: 2019, Seasons: 1}
+	if *items[0] != expected {
+		t.Errorf("item: %+v, expected: %+v", *items[0], expected)
+	}
+}
+
+func TestTMDBSearchErrors(t *testing.T) {
+	tmdb, server, _ := newTestTMDB(t, "key")
+	defer server.Close()
+
+	if items, err := tmdb.Search(context.Background(), []string{"nothing"}); items != nil || err != nil {
+		t.Errorf("nothing found: %v, %v", items, err)
+	}
+
+	tmdb.config.APIKey = ""
+	if _, err := tmdb.Search(context.Background(), []string{"inception"}); err == nil || !strings.Contains(err.Error(), "Invalid API key") {
+		t.Errorf("invalid API key: %v", err)
+	}
+}
+
+func TestTMDBSearchTimeout(t *testing.T) {
+	tmdb, server, _ := newTestTMDB(t, "key")
+	defer server.Close()
+
+	// details of the second TV are out of time
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/tv/94305" {
+			cancel()
+			<-r.Context().Done()
+			return
+		}
+
+		res, err := http.Get(server.URL + r.URL.String())
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer res.Body.Close()
+		data, _ := ioutil.ReadAll(res.Body)
+		w.WriteHeader(res.StatusCode)
+		w.Write(data)
+	}))
+	defer proxy.Close()
+	tmdb.config.BaseURL = proxy.URL
+
+	items, err := tmdb.Search(ctx, []string{"walking", "dead"})
+	if err != nil || len(items) != 1 || items[0].OrgName != "The Walking Dead" {
+		t.Fatalf("search = %v, %v, expected items got before timeout", items, err)
+	}
+
+	// nothing got
+	if items, err := tmdb.Search(ctx, []string{"walking", "dead"}); items != nil || err == nil {
+		t.Errorf("search after timeout = %v, %v", items, err)
+	}
+}
@@ -61,7 +61,7 @@ func (cf *ContactFinder) tickProcess() {
 			// send kademlia2Req
 			targetID := ID{} // random target
 			targetID.generate()
-			cf.pPacketProcessor.sendFindValue(pContact, &targetID)
+			cf.pPacketProcessor.sendFindValue(pContact, &targetID, packetPriorityFinding)
 			c++
 		}
 	}
@@ -9,9 +9,15 @@ import (
 
 const contactTotalNbr = 1000
 
+// Don't overwrite nodes file if we have too few contacts, e.g. stopped soon after start.
+const contactSaveMinNbr = 50
+
+const nodesFileVersion uint32 = 2
+
 // ContactManager x, controlling the time entry
 type ContactManager struct {
 	pPerfs           *Prefs
+	pMetrics         *Metrics
 	pPacketProcessor *PacketProcessor
 
 	liver   ContactLiver
@@ -21,8 +27,9 @@ type ContactManager struct {
 	contactMap map[uint32]*Contact // key is IP
 }
 
-func (cm *ContactManager) start(pPerfs *Prefs, pPacketProcessor *PacketProcessor, pPacketReqGuard *PacketReqGuard) bool {
+func (cm *ContactManager) start(pPerfs *Prefs, pMetrics *Metrics, pPacketProcessor *PacketProcessor, pPacketReqGuard *PacketReqGuard) bool {
 	cm.pPerfs = pPerfs
+	cm.pMetrics = pMetrics
 	cm.pPacketProcessor = pPacketProcessor
 
 	cm.liver.start(pPacketProcessor)
@@ -31,13 +38,26 @@ func (cm *ContactManager) start(pPerfs *Prefs, pPacketProcessor *PacketProcessor
 
 	cm.contactMap = make(map[uint32]*Contact)
 
-	return cm.readFile()
+	// contacts of last run first, then shipped ones
+	if cm.readFile(getRuntimeNodesFileName()) {
+		return true
+	}
+	return cm.readFile(getNodesFileName())
+}
+
+// getNodesFileName is shipped nodes file, which is never written.
+func getNodesFileName() string {
+	path := com.GetConfigPath()
+	return path + "/config/kad/nodes.dat"
 }
 
-func (cm *ContactManager) readFile() bool {
+// getRuntimeNodesFileName is nodes file saved on stop, not in source control.
+func getRuntimeNodesFileName() string {
 	path := com.GetConfigPath()
-	nodeFileName := path + "/config/kad/nodes.dat"
+	return path + "/config/kad/nodes.runtime.dat"
+}
 
+func (cm *ContactManager) readFile(nodeFileName string) bool {
 	f, err := os.Open(nodeFileName)
 	if err != nil {
 		return false
@@ -96,6 +116,53 @@ func (cm *ContactManager) readFile() bool {
 	return true
 }
 
+// writeFile is saving contacts with KAD ID to nodes file in eMule format, so that next start can use them.
+func (cm *ContactManager) writeFile(nodeFileName string) bool {
+	var contacts []*Contact
+	for _, pContact := range cm.contactMap {
+		if pContact.pKadID != nil {
+			contacts = append(contacts, pContact)
+		}
+	}
+
+	if len(contacts) < contactSaveMinNbr {
+		com.HhjLog.Warningf("Only %d contacts, nodes file isn't saved", len(contacts))
+		return false
+	}
+
+	tmpFileName := nodeFileName + ".tmp"
+	f, err := os.Create(tmpFileName)
+	if err != nil {
+		com.HhjLog.Errorf("Create nodes file failed: %s", err)
+		return false
+	}
+
+	binary.Write(f, binary.LittleEndian, uint32(0)) // 0 for new format with version
+	binary.Write(f, binary.LittleEndian, nodesFileVersion)
+	binary.Write(f, binary.LittleEndian, uint32(len(contacts)))
+	for _, pContact := range contacts {
+		binary.Write(f, binary.LittleEndian, pContact.pKadID.getHash())
+		binary.Write(f, binary.LittleEndian, pContact.ip)
+		binary.Write(f, binary.LittleEndian, pContact.updPort)
+		binary.Write(f, binary.LittleEndian, uint16(0)) // TCP port, we don't care
+		binary.Write(f, binary.LittleEndian, pContact.version)
+		pContact.udpKey.writeToFile(f)
+		binary.Write(f, binary.LittleEndian, pContact.bVerified)
+	}
+
+	if err := f.Close(); err != nil {
+		com.HhjLog.Errorf("Write nodes file failed: %s", err)
+		return false
+	}
+
+	if err := os.Rename(tmpFileName, nodeFileName); err != nil {
+		com.HhjLog.Errorf("Replace nodes file failed: %s", err)
+		return false
+	}
+
+	return true
+}
+
 /*
 	Note the overwriting case, it's very tricky.
 	This is only one entry to add contact in routing zone.
@@ -129,6 +196,7 @@ func (cm *ContactManager) addContact(
 			bVerified: bVerified}
 
 		cm.contactMap[ip] = pContact // new one into map
+		cm.pMetrics.inc(metricContactsAdded, "")
 
 		t := time.Now().Unix()
 		cm.liver.add(t, pContact)
@@ -229,6 +297,9 @@ func (cm *ContactManager) tickProcess() {
 		cm.onliner.remove(pContact)
 		cm.finder.remove(pContact)
 	}
+	cm.pMetrics.add(metricContactsDead, "", float64(len(deadContacts)))
+
+	cm.updateMetrics()
 
 	// we still need find more nodes
 	if len(cm.contactMap) < contactTotalNbr {
@@ -237,3 +308,19 @@ func (cm *ContactManager) tickProcess() {
 		//com.HhjLog.Infof("Reach limit of contacts!")
 	}
 }
+
+func (cm *ContactManager) updateMetrics() {
+	if cm.pMetrics == nil {
+		return
+	}
+
+	verifiedNbr := 0
+	for _, pContact := range cm.contactMap {
+		if pContact.bVerified {
+			verifiedNbr++
+		}
+	}
+
+	cm.pMetrics.set(metricContacts, "", float64(len(cm.contactMap)))
+	cm.pMetrics.set(metricContactsVerified, "", float64(verifiedNbr))
+}
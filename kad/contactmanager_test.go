@@ -0,0 +1,68 @@
+package kad
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func newTestContactManager() *ContactManager {
+	cm := &ContactManager{contactMap: make(map[uint32]*Contact)}
+	cm.liver.start(nil)
+	cm.onliner.start(nil)
+	cm.finder.start(nil)
+
+	return cm
+}
+
+// Contacts are of unknown version 0, so that no hello is sent after loaded without network.
+func TestContactManagerNodesFile(t *testing.T) {
+	cm := newTestContactManager()
+	for i := 0; i < contactSaveMinNbr; i++ {
+		var kadID ID
+		kadID.hash[0] = byte(i)
+		cm.addContact(&kadID, uint32(0x01020300+i), uint16(4672+i), 0, &UDPKey{}, true)
+	}
+
+	// contact without KAD ID isn't saved
+	cm.addContact(nil, 0x05060708, 4672, 0, &UDPKey{}, true)
+
+	fileName := filepath.Join(t.TempDir(), "nodes.runtime.dat")
+	if !cm.writeFile(fileName) {
+		t.Fatal("writeFile failed")
+	}
+
+	loaded := newTestContactManager()
+	if !loaded.readFile(fileName) {
+		t.Fatal("readFile failed")
+	}
+	if len(loaded.contactMap) != contactSaveMinNbr {
+		t.Fatalf("readFile got %d contacts, want %d", len(loaded.contactMap), contactSaveMinNbr)
+	}
+
+	for ip, pContact := range cm.contactMap {
+		if pContact.pKadID == nil {
+			continue
+		}
+
+		pLoaded := loaded.contactMap[ip]
+		if pLoaded == nil || pLoaded.pKadID == nil || *pLoaded.pKadID != *pContact.pKadID ||
+			pLoaded.updPort != pContact.updPort {
+			t.Errorf("contact %08x is %+v, want %+v", ip, pLoaded, pContact)
+		}
+
+		// always not verified after loaded
+		if pLoaded != nil && pLoaded.bVerified {
+			t.Errorf("contact %08x is verified", ip)
+		}
+	}
+
+	// too few contacts, file isn't replaced
+	few := newTestContactManager()
+	few.addContact(&ID{}, 0x01020304, 4672, 0, &UDPKey{}, true)
+	if few.writeFile(fileName) {
+		t.Error("writeFile of 1 contact should fail")
+	}
+	if !newTestContactManager().readFile(fileName) {
+		t.Error("nodes file is broken after writeFile failed")
+	}
+}
@@ -73,7 +73,8 @@ func (co *ContactOnliner) remove(pContact *Contact) {
 	}
 }
 
-func (co *ContactOnliner) getSearchContacts(pSearch *Search) []*Contact {
+// get at most @nbr contacts for bootstrapping search
+func (co *ContactOnliner) getSearchContacts(pSearch *Search, nbr int) []*Contact {
 	t := time.Now().Unix()
 
 	// Assume longer online contact will still stay online
@@ -87,17 +88,17 @@ func (co *ContactOnliner) getSearchContacts(pSearch *Search) []*Contact {
 
 			// check can we pass from packet request guard
 			tolerance := pSearch.calcSearchTolerance(container.pContact)
-			opcode := kademlia2SearchKeyReq
+			opcode := pSearch.getSearchOpcode()
 			if tolerance > searchTolerance {
 				opcode = kademlia2Req
 			}
-			if !co.pPacketReqGuard.canPass(t, container.pContact.ip, opcode) {
+			if !co.pPacketReqGuard.canPass(t, container.pContact.ip, opcode, container.pContact.getVersion()) {
 				continue
 			}
 
 			contacts = append(contacts, container.pContact)
-			if len(contacts) == bootstrapSearchContactNbr {
-				break
+			if len(contacts) == nbr {
+				return contacts
 			}
 		}
 	}
@@ -1,6 +1,9 @@
 package kad
 
 import (
+	"context"
+	"errors"
+	"sync"
 	"time"
 )
 
@@ -8,10 +11,13 @@ const (
 	kadTimer               = 1
 	kadPacketReqGuardTimer = 60
 
-	kadSearchReqChSize = 1000
+	kadSearchReqChSize     = 1000
+	kadFileSearchReqChSize = 1000
+	kadSnapshotReqChSize   = 10
 )
 
 // Kad x
+// Start and Stop can be called from any goroutine. Others are only accessed by KAD routine.
 type Kad struct {
 	prefs           Prefs
 	contactManager  ContactManager
@@ -19,38 +25,109 @@ type Kad struct {
 	packetReqGuard  PacketReqGuard
 	searchManager   SearchManager
 
-	socketManager  SocketManager
-	recvCh, sendCh chan *Packet
+	socketManager SocketManager
+	shaper        Shaper
+	recvCh        chan *Packet
 
-	// externs
-	SearchReqCh chan *SearchReq
+	// lifecycle
+	bRunning bool
+	stopCh   chan context.Context // stop request with deadline of draining searches
+	doneCh   chan bool            // KAD routine finished, true if all searches drained
+	lock     sync.Mutex
+
+	// externs, they're kept across restart.
+	// Requests are not read while KAD is stopped, so senders should not block, e.g. select with default or timeout.
+	SearchReqCh     chan *SearchReq
+	FileSearchReqCh chan *FileSearchReq
+	SnapshotReqCh   chan *SnapshotReq
+	Metrics         *Metrics
+
+	// ShaperConfig is limits of outbound traffic, set before Start.
+	// If it's nil, config/kad/shaper.json or DefaultShaperConfig is used.
+	ShaperConfig *ShaperConfig
 }
 
 // Start x
-func (k *Kad) Start() bool {
-	k.SearchReqCh = make(chan *SearchReq, kadSearchReqChSize)
+// It can be called again after Stop.
+func (k *Kad) Start() error {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	if k.bRunning {
+		return errors.New("KAD is already running")
+	}
+
+	if k.SearchReqCh == nil {
+		k.SearchReqCh = make(chan *SearchReq, kadSearchReqChSize)
+		k.FileSearchReqCh = make(chan *FileSearchReq, kadFileSearchReqChSize)
+		k.SnapshotReqCh = make(chan *SnapshotReq, kadSnapshotReqChSize)
+		k.Metrics = NewMetrics()
+	}
 
 	socketChSize := bootstrapSearchContactNbr * int(kademliaFindNode) * int(kademliaFindNode)
 	k.recvCh = make(chan *Packet, socketChSize)
-	k.sendCh = make(chan *Packet, socketChSize)
 
 	// start should be from bottom to up layer
-	k.prefs.start()
-	k.socketManager.start(&k.prefs, k.recvCh, k.sendCh)
-	k.packetReqGuard.start()
-	k.packetProcesser.start(&k.prefs, &k.contactManager, &k.searchManager, &k.packetReqGuard, k.sendCh)
-	k.searchManager.start(&k.packetProcesser, &k.contactManager.onliner)
+	if err := k.prefs.start(); err != nil {
+		return err
+	}
+	k.shaper.start(k.getShaperConfig(), k.Metrics)
+	if err := k.socketManager.start(&k.prefs, k.Metrics, k.recvCh, &k.shaper); err != nil {
+		return err
+	}
+	k.packetReqGuard.start(k.Metrics)
+	k.packetProcesser.start(&k.prefs, k.Metrics, &k.contactManager, &k.searchManager, &k.packetReqGuard, &k.shaper)
+	k.searchManager.start(k.Metrics, &k.packetProcesser, &k.contactManager.onliner)
+
+	if !k.contactManager.start(&k.prefs, k.Metrics, &k.packetProcesser, &k.packetReqGuard) {
+		k.socketManager.stop()
+		return errors.New("KAD has no contacts to bootstrap")
+	}
 
-	k.contactManager.start(&k.prefs, &k.packetProcesser, &k.packetReqGuard)
+	k.stopCh = make(chan context.Context, 1)
+	k.doneCh = make(chan bool, 1)
+	k.bRunning = true
 
 	go k.scheduleRoutine()
 
-	return true
+	return nil
+}
+
+// Stop is stopping KAD gracefully.
+// It stops accepting new searches, drains ongoing searches until @ctx is done, saves contacts, closes sockets
+// and waits for all routines. Error is returned if searches are not drained in time.
+func (k *Kad) Stop(ctx context.Context) error {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	if !k.bRunning {
+		return errors.New("KAD is not running")
+	}
+
+	k.stopCh <- ctx
+	drained := <-k.doneCh
+
+	k.socketManager.stop()
+	k.bRunning = false
+
+	if !drained {
+		return ctx.Err()
+	}
+
+	return nil
 }
 
 func (k *Kad) scheduleRoutine() {
 	tick := time.NewTicker(kadTimer * time.Second)
 	packetReqGuardTimer := time.NewTicker(kadPacketReqGuardTimer * time.Second)
+	defer tick.Stop()
+	defer packetReqGuardTimer.Stop()
+
+	// set to nil during stopping so that no more new searches
+	searchReqCh, fileSearchReqCh := k.SearchReqCh, k.FileSearchReqCh
+
+	var drainCtx context.Context
+	var drainDoneCh <-chan struct{} // nil until stopping
 
 	for {
 		select {
@@ -62,8 +139,32 @@ func (k *Kad) scheduleRoutine() {
 		case <-packetReqGuardTimer.C:
 			k.packetReqGuard.timerProcess()
 
-		case pSearchReq := <-k.SearchReqCh:
+		case pSearchReq := <-searchReqCh:
 			k.searchManager.newSearch(pSearchReq)
+		case pFileSearchReq := <-fileSearchReqCh:
+			k.searchManager.newFileSearch(pFileSearchReq)
+		case pSnapshotReq := <-k.SnapshotReqCh:
+			k.processSnapshotReq(pSnapshotReq)
+
+		case drainCtx = <-k.stopCh:
+			searchReqCh, fileSearchReqCh = nil, nil
+			drainDoneCh = drainCtx.Done()
+		case <-drainDoneCh:
+			k.finish(false)
+			return
+		}
+
+		if drainCtx != nil && k.searchManager.isIdle() {
+			k.finish(true)
+			return
 		}
 	}
 }
+
+// called by KAD routine at last
+func (k *Kad) finish(drained bool) {
+	k.contactManager.writeFile(getRuntimeNodesFileName())
+	k.searchManager.stop()
+
+	k.doneCh <- drained
+}
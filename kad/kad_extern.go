@@ -4,17 +4,111 @@ import (
 	"fmt"
 	"hahajing/com"
 	"log"
+	"path"
+	"strings"
+)
+
+// How to combine results of keyword searches.
+const (
+	// SearchCombineNone is independent search for each target keyword, results are sent as soon as found.
+	SearchCombineNone byte = 0
+
+	// SearchCombineUnion is searching all keywords of each keyword group, files returned by any keyword search are sent.
+	// Results are sent once when searches expire, ranked by how many keyword searches returned each file.
+	SearchCombineUnion byte = 1
+
+	// SearchCombineIntersect is same as SearchCombineUnion, but only files returned by all keyword searches are sent.
+	SearchCombineIntersect byte = 2
 )
 
 // SearchReq x
+// Movies and TV are searched by MyKeywordStruct from Door, results are file links matched with its Items.
+// Raw search is by Query, Filter or FileTypes, all files are sent as Ed2kFileStruct, and they're also classified
+// to file links if MyKeywordStruct is given.
 type SearchReq struct {
 	ResCh           chan *SearchRes
-	MyKeywordStruct *com.MyKeywordStruct
+	MyKeywordStruct *com.MyKeywordStruct // optional for raw search
+	Combine         byte                 // SearchCombineNone by default, never combined for raw search
+
+	// Query is plain keywords for raw search without Door, files must contain all its KAD keywords.
+	Query string
+
+	// Filter is optional for raw search. If only it's set, target keywords are from MyKeywordStruct.
+	Filter *SearchFilter
+
+	// FileTypes is short for Filter with only file types, e.g. com.Ed2kFileTypeAudio, for generic search
+	// of other files than movies and TV. It's ignored if Filter is set.
+	FileTypes []string
+}
+
+// getFilter is Filter, or filter of FileTypes, nil if neither is set.
+func (req *SearchReq) getFilter() *SearchFilter {
+	if req.Filter == nil && req.FileTypes != nil {
+		return &SearchFilter{FileTypes: req.FileTypes}
+	}
+
+	return req.Filter
+}
+
+// SearchFilter is filter of raw search, empty fields are not checked.
+type SearchFilter struct {
+	FileTypes  []string // e.g. com.Ed2kFileTypeAudio
+	Extensions []string // e.g. ".mp3"
+	MinSize    uint64
+	MaxSize    uint64
+	MinAvail   uint32
+}
+
+func (f *SearchFilter) pass(file *Ed2kFileStruct) bool {
+	if f.FileTypes != nil && !containsString(f.FileTypes, file.Type) {
+		return false
+	}
+
+	if f.Extensions != nil && !containsString(f.Extensions, strings.ToLower(path.Ext(file.Name))) {
+		return false
+	}
+
+	if file.Size < f.MinSize || (f.MaxSize != 0 && file.Size > f.MaxSize) {
+		return false
+	}
+
+	return file.Avail >= f.MinAvail
+}
+
+func containsString(ss []string, s string) bool {
+	for _, v := range ss {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+
+	return false
 }
 
 // SearchRes x
 type SearchRes struct {
 	FileLinks []*com.Ed2kFileLink
+	Files     []*Ed2kFileStruct // for raw search
+}
+
+// FileSearchReq is for searching current details of a known file by its hash.
+type FileSearchReq struct {
+	ResCh chan *FileSearchRes // result is sent only once when search expires, must be buffered like FileSearchResChSize
+
+	Hash [16]byte // same order as Ed2kFileStruct.Hash and com.Ed2kFileLink.Hash
+	Size uint64   // 0 if unknown
+}
+
+// FileSearchRes is file details combined from sources and notes of KAD network.
+// If no source and no note found, file is probably dead.
+type FileSearchRes struct {
+	Ed2kFileStruct // Avail is current source count, Name is the most popular name from notes
+
+	OtherNames []string // names from notes except Name
+	Comments   []string
+	Rating     uint8 // average rating from notes, 0 if not rated
+	RatingNbr  int
+	NoteNbr    int
 }
 
 // Ed2kFileStruct x
@@ -25,14 +119,62 @@ type Ed2kFileStruct struct {
 	Size        uint64
 	Type        string
 	Avail       uint32
-	MediaLength uint32
+	MediaLength uint32 // second
+	AICHHash    []byte // the most popular one from publishers, nil if unknown
+
+	// for audio
+	Artist  string
+	Album   string
+	Title   string
+	Bitrate uint32 // kbps
+	Codec   string
+
+	// from notes
+	Rating  uint8
+	Comment string
+
+	sourceType uint8    // not 0 if it's a source of file
+	otherNames []string // same hash published with other names
+
+	fakeScore   int // by names so far, see rescore
+	fakeReasons []string
+
+	filterRule string // rule of content filter which dropped file, empty if passed
 
-	// Do we need publish info and AICH?
+	// Do we need publish info?
+}
+
+const maxOtherNameNbr = 10
+
+// addOtherName is true if @name is new.
+func (f *Ed2kFileStruct) addOtherName(name string) bool {
+	if name == "" || name == f.Name || len(f.otherNames) >= maxOtherNameNbr {
+		return false
+	}
+
+	for _, otherName := range f.otherNames {
+		if otherName == name {
+			return false
+		}
+	}
+
+	f.otherNames = append(f.otherNames, name)
+	return true
+}
+
+// rescore is updating fake score with other names so far, true if score changed.
+func (f *Ed2kFileStruct) rescore() bool {
+	fakeCheckFile := com.FakeCheckFile{Name: f.Name, Size: f.Size, Type: f.Type, MediaLength: f.MediaLength, OtherNames: f.otherNames}
+	score, reasons := com.GetFakeScore(&fakeCheckFile)
+
+	changed := score != f.fakeScore
+	f.fakeScore, f.fakeReasons = score, reasons
+	return changed
 }
 
 // GetEd2kLink x
 func (f *Ed2kFileStruct) GetEd2kLink() string {
-	return com.GetEd2kLink(f.Name, f.Size, f.Hash[:])
+	return com.GetEd2kLinkWithAICH(f.Name, f.Size, f.Hash[:], f.AICHHash)
 }
 
 // GetPrintStr x
@@ -41,5 +183,3 @@ func (f *Ed2kFileStruct) GetPrintStr() string {
 
 	return fmt.Sprintf("Name: %s, Size: %d, Type: %s, Avail:%d\nEd2k: %s\n", f.Name, f.Size, f.Type, f.Avail, f.GetEd2kLink())
 }
-
-
@@ -0,0 +1,117 @@
+package kad
+
+import (
+	"hahajing/com"
+	"testing"
+)
+
+func TestSearchReqFilter(t *testing.T) {
+	mp3 := &Ed2kFileStruct{Name: "Song.MP3", Type: com.Ed2kFileTypeAudio, Size: 5 << 20, Avail: 3}
+	iso := &Ed2kFileStruct{Name: "ubuntu.iso", Type: com.Ed2kFileTypeIso, Size: 2 << 30, Avail: 10}
+
+	tests := []struct {
+		req  SearchReq
+		pass []bool // of mp3 and iso, nil if no filter
+	}{
+		{SearchReq{}, nil},
+		{SearchReq{FileTypes: []string{com.Ed2kFileTypeAudio}}, []bool{true, false}},
+		{SearchReq{Filter: &SearchFilter{Extensions: []string{".mp3", ".iso"}, MinAvail: 5}}, []bool{false, true}},
+		{SearchReq{Filter: &SearchFilter{MaxSize: 1 << 30}}, []bool{true, false}},
+
+		// Filter first
+		{SearchReq{FileTypes: []string{com.Ed2kFileTypeAudio}, Filter: &SearchFilter{MinSize: 1 << 30}}, []bool{false, true}},
+	}
+
+	for i, test := range tests {
+		filter := test.req.getFilter()
+		if filter == nil {
+			if test.pass != nil {
+				t.Errorf("%d: no filter", i)
+			}
+			continue
+		}
+		if test.pass == nil {
+			t.Errorf("%d: unexpected filter %+v", i, filter)
+			continue
+		}
+
+		for j, file := range []*Ed2kFileStruct{mp3, iso} {
+			if got := filter.pass(file); got != test.pass[j] {
+				t.Errorf("%d: pass(%s) = %v, want %v", i, file.Name, got, test.pass[j])
+			}
+		}
+	}
+}
+
+func TestSearchAddFilesRescore(t *testing.T) {
+	s := Search{fileHashMap: make(map[[16]byte]*Ed2kFileStruct)}
+	pMetrics := NewMetrics()
+
+	hash := [16]byte{1}
+	newFile := func(name string) *Ed2kFileStruct {
+		return &Ed2kFileStruct{Hash: hash, Name: name, Size: 700 << 20, Type: com.Ed2kFileTypeVideo}
+	}
+
+	newFiles, rescoredFiles := s.addFiles([]*Ed2kFileStruct{newFile("The.Walking.Dead.S01E01.mkv")}, pMetrics)
+	if len(newFiles) != 1 || rescoredFiles != nil || newFiles[0].fakeScore != 0 {
+		t.Fatalf("first name: new %v, rescored %v", newFiles, rescoredFiles)
+	}
+
+	// related or few unrelated names don't change score
+	newFiles, rescoredFiles = s.addFiles([]*Ed2kFileStruct{newFile("Walking.Dead.S01E01.720p.mkv"), newFile("Inception.2010.mkv"), newFile("Avatar.2009.mkv")}, pMetrics)
+	if newFiles != nil || rescoredFiles != nil {
+		t.Fatalf("few names: new %v, rescored %v", newFiles, rescoredFiles)
+	}
+
+	// more unrelated names of same hash, rescored once for each packet
+	newFiles, rescoredFiles = s.addFiles([]*Ed2kFileStruct{newFile("Titanic.1997.mkv"), newFile("Interstellar.2014.mkv")}, pMetrics)
+	if newFiles != nil || len(rescoredFiles) != 1 {
+		t.Fatalf("many names: new %v, rescored %v", newFiles, rescoredFiles)
+	}
+	if pFile := rescoredFiles[0]; pFile.fakeScore == 0 || len(pFile.fakeReasons) == 0 {
+		t.Errorf("fake score = %d %v, want > 0", pFile.fakeScore, pFile.fakeReasons)
+	}
+
+	// same name again isn't new
+	if _, rescoredFiles = s.addFiles([]*Ed2kFileStruct{newFile("Titanic.1997.mkv")}, pMetrics); rescoredFiles != nil {
+		t.Errorf("same name rescored %v", rescoredFiles)
+	}
+}
+
+func TestSearchContentFilterOfRaw(t *testing.T) {
+	s := Search{fileHashMap: make(map[[16]byte]*Ed2kFileStruct)}
+	files := []*Ed2kFileStruct{
+		{Hash: [16]byte{1}, Name: "Love.Song.mp3", Type: com.Ed2kFileTypeAudio, Size: 5 << 20},
+		{Hash: [16]byte{2}, Name: "Sex.Song.mp3", Type: com.Ed2kFileTypeAudio, Size: 5 << 20},
+	}
+
+	// all are kept, dropped one is counted
+	newFiles, _ := s.addFiles(files, NewMetrics())
+	if len(newFiles) != 2 || s.getPassedFileNbr() != 1 || s.getFilteredNbrs()["keyword"] != 1 {
+		t.Fatalf("new %d, passed %d, filtered %v", len(newFiles), s.getPassedFileNbr(), s.getFilteredNbrs())
+	}
+
+	tests := []struct {
+		req     SearchReq
+		wantNbr int
+	}{
+		{SearchReq{Query: "song"}, 2},
+		{SearchReq{FileTypes: []string{com.Ed2kFileTypeAudio}}, 2},
+		{SearchReq{Query: "song", Filter: &SearchFilter{MinSize: 1 << 20}}, 1},
+	}
+
+	for i, test := range tests {
+		resCh := make(chan *SearchRes, 1)
+		pSearch := &Search{resCh: resCh, bRaw: true, pFilter: test.req.getFilter(), queryKeywords: getKadKeywords(test.req.Query),
+			bContentFilter: test.req.Filter != nil}
+		pSearch.sendFiles(newFiles)
+
+		nbr := 0
+		if len(resCh) > 0 {
+			nbr = len((<-resCh).Files)
+		}
+		if nbr != test.wantNbr {
+			t.Errorf("%d: files sent = %d, want %d", i, nbr, test.wantNbr)
+		}
+	}
+}
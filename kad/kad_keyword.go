@@ -0,0 +1,89 @@
+package kad
+
+import (
+	"bytes"
+	"hahajing/com"
+	"strings"
+	"unicode"
+)
+
+// Keyword rules of eMule(CSearchManager::GetWords and KadGetKeywordHash), publishers hash file names by them.
+// Keyword hash mismatched with publishers means nothing found.
+const (
+	kadInvalidKeywordChars = " ()[]{}<>,._-!?:;\\/\"" // separators of keywords
+	kadMinKeywordBytes     = 3                        // in UTF-8
+	kadFileExtChars        = 3                        // last keyword of this length is thought as file extension
+)
+
+// eMule lower-cases by fixed table of UTF-16 code units without locale,
+// characters outside BMP are surrogate pairs which are never changed.
+func kadKeywordToLower(s string) string {
+	var buf bytes.Buffer
+	for _, c := range s {
+		if c <= 0xFFFF {
+			c = unicode.ToLower(c)
+		}
+		buf.WriteRune(c)
+	}
+
+	return buf.String()
+}
+
+// length in UTF-16 code units like eMule
+func kadKeywordCharNbr(s string) int {
+	n := 0
+	for _, c := range s {
+		n++
+		if c > 0xFFFF {
+			n++
+		}
+	}
+
+	return n
+}
+
+// getKadKeywords is splitting file name or search string to KAD keywords like eMule.
+// Keywords are lower case, duplicated one is moved to the last.
+func getKadKeywords(s string) []string {
+	var keywords []string
+	lastToken := "" // the last token between separators, might be empty
+
+	start := 0
+	for i, c := range s + " " { // tail separator for the last token
+		if i < len(s) && !strings.ContainsRune(kadInvalidKeywordChars, c) {
+			continue
+		}
+
+		lastToken = s[start:i]
+		start = i + len(string(c))
+
+		if len(lastToken) < kadMinKeywordBytes { // UTF-8 bytes
+			continue
+		}
+
+		keyword := kadKeywordToLower(lastToken)
+		for j, k := range keywords {
+			if k == keyword {
+				keywords = append(keywords[:j], keywords[j+1:]...)
+				break
+			}
+		}
+		keywords = append(keywords, keyword)
+	}
+
+	// it's file extension, 3 characters in 3 bytes, so CJK word like 捉妖记 isn't
+	if len(keywords) > 1 && kadKeywordCharNbr(lastToken) == kadFileExtChars && len(lastToken) == kadFileExtChars {
+		keywords = keywords[:len(keywords)-1]
+	}
+
+	return keywords
+}
+
+// getKadKeywordHash is MD4 hash of lower-case keyword in UTF-8, in KAD order.
+func getKadKeywordHash(keyword string) [16]byte {
+	md4 := Md4Sum{}
+	md4.calculate([]byte(kadKeywordToLower(keyword)))
+
+	// change to big endian for each uint32
+	return com.ConvertEd2kHash32(md4.getRawHash())
+}
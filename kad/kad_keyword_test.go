@@ -0,0 +1,68 @@
+package kad
+
+import (
+	"fmt"
+	"hahajing/com"
+	"strings"
+	"testing"
+)
+
+func TestGetKadKeywords(t *testing.T) {
+	tests := []struct {
+		input    string
+		keywords []string
+	}{
+		{"abc", []string{"abc"}},
+		{"ABC", []string{"abc"}},
+		{"The.Walking.Dead.S01E01.720p.mkv", []string{"the", "walking", "dead", "s01e01", "720p"}},
+		{"a_b-c", nil},
+		{"Amélie (2001) [DVDRip].avi", []string{"amélie", "2001", "dvdrip"}},
+		{"ÉCOLE école", []string{"école"}},
+		{"ПРИВЕТ мир.txt", []string{"привет", "мир"}},
+		{"捉妖记2 国语中字.rmvb", []string{"捉妖记2", "国语中字", "rmvb"}},
+		{"dead dead walking", []string{"dead", "walking"}},
+		{"walking dead dead", []string{"walking", "dead"}},
+		{"abc,def", []string{"abc"}},
+		{"abc,def,", []string{"abc", "def"}},
+
+		// 3 characters but not 3 bytes, it isn't file extension
+		{"国语中字 捉妖记", []string{"国语中字", "捉妖记"}},
+		{"ПРИВЕТ мир", []string{"привет", "мир"}},
+		{"Amélie été", []string{"amélie", "été"}},
+	}
+
+	for _, test := range tests {
+		keywords := getKadKeywords(test.input)
+		if strings.Join(keywords, "|") != strings.Join(test.keywords, "|") {
+			t.Errorf("getKadKeywords(%q) = %q, expected %q", test.input, keywords, test.keywords)
+		}
+	}
+}
+
+// Hash is MD4 of lower-case keyword in UTF-8 like eMule KadGetKeywordHash, in ED2K order.
+func TestGetKadKeywordHash(t *testing.T) {
+	tests := []struct {
+		keyword string
+		hash    string
+	}{
+		{"abc", "A448017AAF21D8525FC10AE87AA6729D"},                        // RFC 1320
+		{"abcdefghijklmnopqrstuvwxyz", "D79E1C308AA5BBCDEEA8ED63DF412DA9"}, // RFC 1320
+		{"ABC", "A448017AAF21D8525FC10AE87AA6729D"},
+		{"捉妖记", "9FF2E5852C554B8C814635D0D1F93190"},
+		{"捉妖记2", "BFB42B7D673DB58924C17AFEA84B773D"},
+		{"国语中字", "5855ABFA323770482CB1AEB47723370E"},
+		{"行尸走肉", "926B153E84FA444FAAC13897D685FB55"},
+		{"琅琊榜", "FE86963C7A1516C1D01E090DF289895A"},
+		{"Amélie", "7A6C695C21883EE5A2271C1523B76611"},
+		{"ÉCOLE", "61D2106990C2FD4F769AAE72A21CBA17"},
+		{"ПРИВЕТ", "D1B696647E0677FF8C43EB8A311A4322"},
+		{"мир", "4CDAA100633161519AB8FE15658D7837"},
+	}
+
+	for _, test := range tests {
+		hash := getKadKeywordHash(test.keyword)
+		if hashStr := fmt.Sprintf("%X", com.ConvertEd2kHash32(hash[:])); hashStr != test.hash {
+			t.Errorf("getKadKeywordHash(%q) = %s, expected %s", test.keyword, hashStr, test.hash)
+		}
+	}
+}
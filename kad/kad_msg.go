@@ -2,6 +2,7 @@ package kad
 
 import (
 	"encoding/binary"
+	"hahajing/com"
 )
 
 // Kademlia2HelloResMsg x
@@ -181,20 +182,71 @@ func (m *Kademlia2SearchResMsg) setFileSize(pFileStruct *Ed2kFileStruct, pTag *T
 	}
 }
 
+// get the most popular AICH hash
+func (m *Kademlia2SearchResMsg) setFileAICHHash(pFileStruct *Ed2kFileStruct, pTag *Tag) {
+	v, ok := pTag.value.([]byte)
+	if !ok {
+		return
+	}
+
+	bi := ByteIO{buf: v}
+	if !bi.check(1) {
+		return
+	}
+	count := bi.readUint8()
+
+	var maxPopularity uint8
+	for ; count > 0; count-- {
+		if !bi.check(1 + aichHashSize) {
+			return
+		}
+		popularity := bi.readUint8()
+		hash := bi.readBytes(aichHashSize)
+
+		if popularity > maxPopularity {
+			maxPopularity = popularity
+			pFileStruct.AICHHash = hash
+		}
+	}
+}
+
 func (m *Kademlia2SearchResMsg) setFileParams(pFileStruct *Ed2kFileStruct, tags []*Tag) {
 
 	for _, pTag := range tags {
 		switch pTag.name {
 		case tagFileName:
-			pFileStruct.Name = pTag.value.(string)
+			pFileStruct.Name, _ = pTag.value.(string)
 		case tagFileSize:
 			m.setFileSize(pFileStruct, pTag)
 		case tagFileType:
-			pFileStruct.Type = pTag.value.(string)
+			pFileStruct.Type, _ = pTag.value.(string)
 		case tagSources:
 			pFileStruct.Avail = void2Uint32(pTag.value)
 		case tagMediaLength:
 			pFileStruct.MediaLength = void2Uint32(pTag.value)
+		case tagMediaArtist:
+			pFileStruct.Artist, _ = pTag.value.(string)
+		case tagMediaAlbum:
+			pFileStruct.Album, _ = pTag.value.(string)
+		case tagMediaTitle:
+			pFileStruct.Title, _ = pTag.value.(string)
+		case tagMediaBitrate:
+			pFileStruct.Bitrate = void2Uint32(pTag.value)
+		case tagMediaCodec:
+			pFileStruct.Codec, _ = pTag.value.(string)
+		case tagKadAICHHashResult:
+			m.setFileAICHHash(pFileStruct, pTag)
+		case tagFileRating:
+			pFileStruct.Rating = uint8(void2Uint32(pTag.value))
+		case tagDescription:
+			pFileStruct.Comment, _ = pTag.value.(string)
+		case tagSourceType:
+			pFileStruct.sourceType = uint8(void2Uint32(pTag.value))
 		}
 	}
+
+	// not all publishers give file type
+	if pFileStruct.Type == "" {
+		pFileStruct.Type = com.GetEd2kFileTypeByExt(pFileStruct.Name)
+	}
 }
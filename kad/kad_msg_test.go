@@ -0,0 +1,199 @@
+package kad
+
+import (
+	"bytes"
+	"hahajing/com"
+	"strings"
+	"testing"
+)
+
+// one file of KADEMLIA2_SEARCH_RES in wire format, tags are like eMule publishes.
+var kadSearchResFile = []byte{
+	// file hash
+	0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0A, 0x0B, 0x0C, 0x0D, 0x0E, 0x0F, 0x10,
+
+	6, // tags
+
+	// TAG_FILENAME <string> "a.mkv"
+	tagTypeString, 0x01, 0x00, 0x01, 0x05, 0x00, 'a', '.', 'm', 'k', 'v',
+
+	// TAG_FILESIZE <uint32> 1000000
+	tagTypeUint32, 0x01, 0x00, 0x02, 0x40, 0x42, 0x0F, 0x00,
+
+	// TAG_SOURCES <uint8> 12
+	tagTypeUint8, 0x01, 0x00, 0x15, 12,
+
+	// TAG_FILETYPE <string> "Video"
+	tagTypeString, 0x01, 0x00, 0x03, 0x05, 0x00, 'V', 'i', 'd', 'e', 'o',
+
+	// TAG_KADAICHHASHRESULT <bsob> 2 AICH hashes, the second is more popular
+	tagTypeBsob, 0x01, 0x00, 0x37, 1 + 2*(1+aichHashSize),
+	2,
+	1, 0xA1, 0xA1, 0xA1, 0xA1, 0xA1, 0xA1, 0xA1, 0xA1, 0xA1, 0xA1, 0xA1, 0xA1, 0xA1, 0xA1, 0xA1, 0xA1, 0xA1, 0xA1, 0xA1, 0xA1,
+	5, 0xB2, 0xB2, 0xB2, 0xB2, 0xB2, 0xB2, 0xB2, 0xB2, 0xB2, 0xB2, 0xB2, 0xB2, 0xB2, 0xB2, 0xB2, 0xB2, 0xB2, 0xB2, 0xB2, 0xB2,
+
+	// TAG_FILERATING <uint8> 4
+	tagTypeUint8, 0x01, 0x00, 0xF7, 4,
+}
+
+func TestKademlia2SearchResMsg(t *testing.T) {
+	buf := make([]byte, 16+16) // contact ID and target ID
+	buf = append(buf, 1, 0)    // 1 file
+	buf = append(buf, kadSearchResFile...)
+
+	var msg Kademlia2SearchResMsg
+	if !msg.set(&Packet{buf: buf}) || len(msg.files) != 1 {
+		t.Fatalf("parse search result failed: %d files", len(msg.files))
+	}
+
+	file := msg.files[0]
+	if file.Name != "a.mkv" || file.Size != 1000000 || file.Avail != 12 || file.Type != "Video" || file.Rating != 4 {
+		t.Errorf("file tags: %+v", *file)
+	}
+
+	if !bytes.Equal(file.AICHHash, bytes.Repeat([]byte{0xB2}, aichHashSize)) {
+		t.Fatalf("AICH hash = %X, expected the most popular one", file.AICHHash)
+	}
+
+	if link := file.GetEd2kLink(); !strings.Contains(link, "|h=WKZLFMVSWKZLFMVSWKZLFMVSWKZLFMVS|/") {
+		t.Errorf("ED2K link without AICH hash: %s", link)
+	}
+
+	fileLink := com.Ed2kFileLink{Name: file.Name, Size: file.Size, Hash: file.Hash[:], AICHHash: file.AICHHash}
+	if link := fileLink.GetMagnetLink(); !strings.Contains(link, "xt=urn:aich:WKZLFMVSWKZLFMVSWKZLFMVSWKZLFMVS") {
+		t.Errorf("magnet link without AICH hash: %s", link)
+	}
+}
+
+// answers of file search by same peer, a source and a note.
+var kadFileSearchResFiles = []byte{
+	// source, hash is source ID
+	0x21, 0x22, 0x23, 0x24, 0x25, 0x26, 0x27, 0x28, 0x29, 0x2A, 0x2B, 0x2C, 0x2D, 0x2E, 0x2F, 0x30,
+	1,
+	// TAG_SOURCETYPE <uint8> 1
+	tagTypeUint8, 0x01, 0x00, 0xFF, 1,
+
+	// note, hash is publisher ID
+	0x21, 0x22, 0x23, 0x24, 0x25, 0x26, 0x27, 0x28, 0x29, 0x2A, 0x2B, 0x2C, 0x2D, 0x2E, 0x2F, 0x30,
+	4,
+	// TAG_FILENAME <string> "a.mkv"
+	tagTypeString, 0x01, 0x00, 0x01, 0x05, 0x00, 'a', '.', 'm', 'k', 'v',
+	// TAG_FILESIZE <uint32> 1000000
+	tagTypeUint32, 0x01, 0x00, 0x02, 0x40, 0x42, 0x0F, 0x00,
+	// TAG_FILERATING <uint8> 5
+	tagTypeUint8, 0x01, 0x00, 0xF7, 5,
+	// TAG_DESCRIPTION <string> "good"
+	tagTypeString, 0x01, 0x00, 0x0B, 0x04, 0x00, 'g', 'o', 'o', 'd',
+}
+
+func TestKademlia2SearchResMsgSourceAndNote(t *testing.T) {
+	buf := make([]byte, 16+16)
+	buf = append(buf, 2, 0)
+	buf = append(buf, kadFileSearchResFiles...)
+
+	var msg Kademlia2SearchResMsg
+	if !msg.set(&Packet{buf: buf}) || len(msg.files) != 2 {
+		t.Fatalf("parse file search result failed: %d files", len(msg.files))
+	}
+
+	source, note := msg.files[0], msg.files[1]
+	if source.sourceType != 1 {
+		t.Errorf("source: %+v", *source)
+	}
+	if note.sourceType != 0 || note.Name != "a.mkv" || note.Size != 1000000 || note.Rating != 5 || note.Comment != "good" {
+		t.Errorf("note: %+v", *note)
+	}
+
+	// source and note from same peer are both kept
+	s := Search{searchType: searchTypeFile, targetID: ID{hash: [16]byte{1}}, fileHashMap: make(map[[16]byte]*Ed2kFileStruct)}
+	s.addFileAnswers(msg.files)
+	s.addFileAnswers(msg.files) // answered again
+	if len(s.files) != 2 {
+		t.Fatalf("answers = %d, want 2", len(s.files))
+	}
+
+	res := s.getFileSearchRes()
+	if res.Avail != 1 || res.NoteNbr != 1 || res.Name != "a.mkv" || res.Size != 1000000 || res.Rating != 5 || len(res.Comments) != 1 {
+		t.Errorf("file search result: %+v", *res)
+	}
+}
+
+func TestGetFileSearchRes(t *testing.T) {
+	s := Search{searchType: searchTypeFile, targetID: ID{hash: [16]byte{1}}, fileSize: 0, fileHashMap: make(map[[16]byte]*Ed2kFileStruct)}
+	s.addFileAnswers([]*Ed2kFileStruct{
+		{Hash: [16]byte{1}, sourceType: 1},
+		{Hash: [16]byte{2}, sourceType: 4},
+		{Hash: [16]byte{3}, Name: "b.avi", Size: 700, Rating: 2},
+		{Hash: [16]byte{4}, Name: "a.mkv", Size: 700, Rating: 4, Comment: "ok"},
+		{Hash: [16]byte{5}, Name: "a.mkv", Size: 700},
+	})
+
+	res := s.getFileSearchRes()
+	if res.Hash != [16]byte{1} || res.Avail != 2 || res.NoteNbr != 3 || res.Size != 700 {
+		t.Errorf("counts: %+v", *res)
+	}
+
+	// the most popular name, rating of rated notes only
+	if res.Name != "a.mkv" || len(res.OtherNames) != 1 || res.OtherNames[0] != "b.avi" {
+		t.Errorf("names: %s %v", res.Name, res.OtherNames)
+	}
+	if res.Rating != 3 || res.RatingNbr != 2 || len(res.Comments) != 1 || res.Comments[0] != "ok" {
+		t.Errorf("rating %d of %d, comments %v", res.Rating, res.RatingNbr, res.Comments)
+	}
+
+	// no answer, probably dead
+	empty := Search{searchType: searchTypeFile, targetID: ID{hash: [16]byte{2}}, fileSize: 100}
+	if res := empty.getFileSearchRes(); res.Avail != 0 || res.NoteNbr != 0 || res.Size != 100 || res.Name != "" {
+		t.Errorf("empty: %+v", *res)
+	}
+}
+
+// audio file published without file type, eMule publishes tags from ID3.
+var kadSearchResAudioFile = []byte{
+	0x31, 0x32, 0x33, 0x34, 0x35, 0x36, 0x37, 0x38, 0x39, 0x3A, 0x3B, 0x3C, 0x3D, 0x3E, 0x3F, 0x40,
+
+	7, // tags
+
+	// TAG_FILENAME <string> "b.mp3"
+	tagTypeString, 0x01, 0x00, 0x01, 0x05, 0x00, 'b', '.', 'm', 'p', '3',
+
+	// TAG_MEDIA_ARTIST <string> "王菲"
+	tagTypeString, 0x01, 0x00, 0xD0, 0x06, 0x00, 0xE7, 0x8E, 0x8B, 0xE8, 0x8F, 0xB2,
+
+	// TAG_MEDIA_ALBUM <string> "Faye"
+	tagTypeString, 0x01, 0x00, 0xD1, 0x04, 0x00, 'F', 'a', 'y', 'e',
+
+	// TAG_MEDIA_TITLE <string> "Eyes"
+	tagTypeString, 0x01, 0x00, 0xD2, 0x04, 0x00, 'E', 'y', 'e', 's',
+
+	// TAG_MEDIA_LENGTH <uint16> 245
+	tagTypeUint16, 0x01, 0x00, 0xD3, 0xF5, 0x00,
+
+	// TAG_MEDIA_BITRATE <uint32> 320
+	tagTypeUint32, 0x01, 0x00, 0xD4, 0x40, 0x01, 0x00, 0x00,
+
+	// TAG_MEDIA_CODEC <string> "mp3"
+	tagTypeString, 0x01, 0x00, 0xD5, 0x03, 0x00, 'm', 'p', '3',
+}
+
+func TestKademlia2SearchResMsgAudio(t *testing.T) {
+	buf := make([]byte, 16+16)
+	buf = append(buf, 1, 0)
+	buf = append(buf, kadSearchResAudioFile...)
+
+	var msg Kademlia2SearchResMsg
+	if !msg.set(&Packet{buf: buf}) || len(msg.files) != 1 {
+		t.Fatalf("parse search result failed: %d files", len(msg.files))
+	}
+
+	file := msg.files[0]
+	if file.Artist != "王菲" || file.Album != "Faye" || file.Title != "Eyes" || file.MediaLength != 245 ||
+		file.Bitrate != 320 || file.Codec != "mp3" {
+		t.Errorf("audio tags: %+v", *file)
+	}
+
+	// type by extension if not published
+	if file.Type != com.Ed2kFileTypeAudio {
+		t.Errorf("type = %q, want %q", file.Type, com.Ed2kFileTypeAudio)
+	}
+}
@@ -32,7 +32,7 @@ const (
 
 	kademlia2SearchKeyReq    byte = 0x33 // search keyword
 	kademlia2SearchSourceReq byte = 0x34 //
-	kademlia2SeachNotesReq   byte = 0x35 //
+	kademlia2SearchNotesReq  byte = 0x35 //
 	kademlia2SearchRes       byte = 0x3B //
 
 	kademlia2PublishKeyReq    byte = 0x43 //
@@ -50,6 +50,8 @@ const (
 	kademliaFindValue     uint8 = 0x02
 	kademliaFindNode      uint8 = 0x0B
 	kademliaFindValueMore uint8 = kademliaFindNode
+
+	aichHashSize = 20
 )
 
 const minSupportContactVersion = kademliaVersion3_47b
@@ -71,6 +73,10 @@ func getOpcodeStr(opcode byte) string {
 
 	case kademlia2SearchKeyReq:
 		opcodeStr = "kademlia2SearchKeyReq"
+	case kademlia2SearchSourceReq:
+		opcodeStr = "kademlia2SearchSourceReq"
+	case kademlia2SearchNotesReq:
+		opcodeStr = "kademlia2SearchNotesReq"
 	case kademlia2SearchRes:
 		opcodeStr = "kademlia2SearchRes"
 	}
@@ -27,14 +27,14 @@ type Prefs struct {
 	tLastContact int64 // time of last packet I received from other client, I use it to track if I'm still online
 }
 
-func (p *Prefs) start() {
+func (p *Prefs) start() error {
 	p.kadID.generate()
 	p.udpKey = random32()
 	p.tcpPort = localTCPPort
 
 	p.localUDPPort = localUDPPort
 
-	p.initLocalIP()
+	return p.initLocalIP()
 }
 
 func (p *Prefs) getUDPVerifyKey(targetIP uint32) uint32 {
@@ -102,10 +102,11 @@ func (p *Prefs) setPublicIP(ip uint32) {
 }
 
 // Get preferred outbound ip of this machine
-func (p *Prefs) initLocalIP() {
+func (p *Prefs) initLocalIP() error {
 	conn, err := net.Dial("udp", "8.8.8.8:80")
 	if err != nil {
 		com.HhjLog.Critical(err)
+		return err
 	}
 	defer conn.Close()
 
@@ -114,4 +115,6 @@ func (p *Prefs) initLocalIP() {
 	com.HhjLog.Infof("Local outbound IP: %s\n", localAddr.IP.String())
 
 	p.localIP = ip2I(localAddr.IP)
+
+	return nil
 }
@@ -15,11 +15,21 @@ const (
 	tagTypeUint64    byte = 0x0B
 
 	// tag name of file tags
-	tagFileName    = "\x01" // <string>
-	tagFileSize    = "\x02" // <uint32>
-	tagFileType    = "\x03" // <string>
-	tagSources     = "\x15" // <uint32>
-	tagMediaLength = "\xD3" // <uint32> !!!
+	tagFileName     = "\x01" // <string>
+	tagFileSize     = "\x02" // <uint32>
+	tagFileType     = "\x03" // <string>
+	tagSources      = "\x15" // <uint32>
+	tagMediaArtist  = "\xD0" // <string>
+	tagMediaAlbum   = "\xD1" // <string>
+	tagMediaTitle   = "\xD2" // <string>
+	tagMediaLength  = "\xD3" // <uint32> !!!
+	tagMediaBitrate = "\xD4" // <uint32>
+	tagMediaCodec   = "\xD5" // <string>
+	tagDescription  = "\x0B" // <string>
+	tagFileRating   = "\xF7" // <uint8>
+	tagSourceType   = "\xFF" // <uint8>
+
+	tagKadAICHHashResult = "\x37" // <Count 1>{<Publishers 1><AICH Hash> Count}
 )
 
 // Tag x
@@ -30,7 +40,7 @@ type Tag struct {
 }
 
 func readHashTag(bi *ByteIO) *[]byte {
-	if bi.check(16) {
+	if !bi.check(16) {
 		return nil
 	}
 
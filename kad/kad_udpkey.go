@@ -17,6 +17,11 @@ func (k *UDPKey) readFromFile(r io.Reader) {
 	binary.Read(r, binary.LittleEndian, &k.ip)
 }
 
+func (k *UDPKey) writeToFile(w io.Writer) {
+	binary.Write(w, binary.LittleEndian, k.key)
+	binary.Write(w, binary.LittleEndian, k.ip)
+}
+
 func (k *UDPKey) getKeyValue(ip uint32) uint32 {
 	if ip == k.ip {
 		return k.key
@@ -0,0 +1,257 @@
+package kad
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const metricsPrefix = "hahajing_kad_"
+
+const (
+	metricCounter   = "counter"
+	metricGauge     = "gauge"
+	metricHistogram = "histogram"
+)
+
+// metric names
+const (
+	metricPacketsSent          = "packets_sent_total"
+	metricPacketsReceived      = "packets_received_total"
+	metricBytesSent            = "bytes_sent_total"
+	metricBytesReceived        = "bytes_received_total"
+	metricPacketsDropped       = "packets_dropped_total"
+	metricMessagesInvalid      = "messages_invalid_total"
+	metricContacts             = "contacts"
+	metricContactsVerified     = "contacts_verified"
+	metricContactsAdded        = "contacts_added_total"
+	metricContactsDead         = "contacts_dead_total"
+	metricGuardRejections      = "packet_guard_rejections_total"
+	metricGuardTrackedIPs      = "packet_guard_tracked_ips"
+	metricGuardBackoffs        = "packet_guard_backoffs_total"
+	metricShaperQueued         = "shaper_queued_packets"
+	metricShaperPackets        = "shaper_packets_total"
+	metricShaperDelay          = "shaper_delay_seconds_total"
+	metricSearches             = "searches_total"
+	metricSearchesActive       = "searches_active"
+	metricSearchDuration       = "search_duration_seconds"
+	metricSearchFirstResult    = "search_first_result_seconds"
+	metricSearchResults        = "search_results"
+	metricSearchesWithoutFiles = "searches_without_results_total"
+	metricFilesFiltered        = "files_filtered_total"
+)
+
+var searchSecondsBuckets = []float64{0.1, 0.25, 0.5, 1, 2, 3, 5, 10}
+var searchResultsBuckets = []float64{0, 1, 5, 10, 25, 50, 100, 250}
+
+// Metric is one metric family with at most one label.
+type Metric struct {
+	name      string
+	help      string
+	typ       string
+	labelName string
+
+	values map[string]float64 // label value: value, label value is empty if no label
+
+	// for histogram
+	buckets      []float64
+	bucketCounts map[string][]uint64
+	counts       map[string]uint64
+}
+
+// Metrics is registry of KAD metrics, which is exposed in Prometheus text format.
+// It's updated by socket routines and KAD routine, so it's synchronized.
+type Metrics struct {
+	metricMap map[string]*Metric
+
+	lock sync.Mutex
+}
+
+// NewMetrics x
+func NewMetrics() *Metrics {
+	m := Metrics{metricMap: make(map[string]*Metric)}
+
+	m.register(metricPacketsSent, metricCounter, "opcode", "KAD packets sent by opcode.", nil)
+	m.register(metricPacketsReceived, metricCounter, "opcode", "KAD packets received by opcode.", nil)
+	m.register(metricBytesSent, metricCounter, "", "UDP bytes sent.", nil)
+	m.register(metricBytesReceived, metricCounter, "", "UDP bytes received.", nil)
+	m.register(metricPacketsDropped, metricCounter, "reason", "Packets dropped by socket.", nil)
+	m.register(metricMessagesInvalid, metricCounter, "opcode", "Received KAD messages failed to decode.", nil)
+
+	m.register(metricContacts, metricGauge, "", "Contacts in routing table.", nil)
+	m.register(metricContactsVerified, metricGauge, "", "Contacts verified via hello.", nil)
+	m.register(metricContactsAdded, metricCounter, "", "Contacts added into routing table.", nil)
+	m.register(metricContactsDead, metricCounter, "", "Contacts removed as dead.", nil)
+
+	m.register(metricGuardRejections, metricCounter, "opcode", "Requests rejected by packet request guard.", nil)
+	m.register(metricGuardTrackedIPs, metricGauge, "", "Remote IPs tracked by packet request guard.", nil)
+	m.register(metricGuardBackoffs, metricCounter, "", "Backoffs from remote IPs which stop answering.", nil)
+
+	m.register(metricShaperQueued, metricGauge, "priority", "Packets waiting in shaper by priority.", nil)
+	m.register(metricShaperPackets, metricCounter, "priority", "Packets passed shaper by priority.", nil)
+	m.register(metricShaperDelay, metricCounter, "", "Time of sending delayed by shaper.", nil)
+
+	m.register(metricSearches, metricCounter, "type", "Searches started by type.", nil)
+	m.register(metricSearchesActive, metricGauge, "", "Ongoing searches.", nil)
+	m.register(metricSearchDuration, metricHistogram, "", "Search duration.", searchSecondsBuckets)
+	m.register(metricSearchFirstResult, metricHistogram, "", "Time to the first result of search.", searchSecondsBuckets)
+	m.register(metricSearchResults, metricHistogram, "", "Files found per search.", searchResultsBuckets)
+	m.register(metricSearchesWithoutFiles, metricCounter, "", "Searches finished without any file.", nil)
+	m.register(metricFilesFiltered, metricCounter, "rule", "Found files dropped by content filter.", nil)
+
+	return &m
+}
+
+func (m *Metrics) register(name, typ, labelName, help string, buckets []float64) {
+	m.metricMap[name] = &Metric{
+		name:         name,
+		help:         help,
+		typ:          typ,
+		labelName:    labelName,
+		values:       make(map[string]float64),
+		buckets:      buckets,
+		bucketCounts: make(map[string][]uint64),
+		counts:       make(map[string]uint64)}
+}
+
+// All update methods are nil safe so that components can work without metrics.
+
+func (m *Metrics) add(name, label string, v float64) {
+	if m == nil {
+		return
+	}
+
+	m.lock.Lock()
+	m.metricMap[name].values[label] += v
+	m.lock.Unlock()
+}
+
+func (m *Metrics) inc(name, label string) {
+	m.add(name, label, 1)
+}
+
+func (m *Metrics) set(name, label string, v float64) {
+	if m == nil {
+		return
+	}
+
+	m.lock.Lock()
+	m.metricMap[name].values[label] = v
+	m.lock.Unlock()
+}
+
+func (m *Metrics) observe(name, label string, v float64) {
+	if m == nil {
+		return
+	}
+
+	m.lock.Lock()
+
+	metric := m.metricMap[name]
+	counts := metric.bucketCounts[label]
+	if counts == nil {
+		counts = make([]uint64, len(metric.buckets))
+		metric.bucketCounts[label] = counts
+	}
+
+	for i, bound := range metric.buckets {
+		if v <= bound {
+			counts[i]++
+		}
+	}
+	metric.counts[label]++
+	metric.values[label] += v // sum
+
+	m.lock.Unlock()
+}
+
+// WritePrometheus is writing all metrics in Prometheus text format.
+func (m *Metrics) WritePrometheus(w io.Writer) error {
+	buf := bytes.Buffer{}
+
+	m.lock.Lock()
+
+	var names []string
+	for name := range m.metricMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		m.metricMap[name].write(&buf)
+	}
+
+	m.lock.Unlock()
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func (metric *Metric) write(buf *bytes.Buffer) {
+	fullName := metricsPrefix + metric.name
+	fmt.Fprintf(buf, "# HELP %s %s\n", fullName, metricHelpReplacer.Replace(metric.help))
+	fmt.Fprintf(buf, "# TYPE %s %s\n", fullName, metric.typ)
+
+	var labels []string
+	for label := range metric.values {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+
+	// always have a sample for metric without label
+	if len(labels) == 0 && metric.labelName == "" {
+		labels = append(labels, "")
+	}
+
+	for _, label := range labels {
+		if metric.typ != metricHistogram {
+			fmt.Fprintf(buf, "%s%s %s\n", fullName, metric.getLabelStr(label, ""), formatMetricValue(metric.values[label]))
+			continue
+		}
+
+		counts := metric.bucketCounts[label]
+		for i, bound := range metric.buckets {
+			var count uint64
+			if counts != nil {
+				count = counts[i]
+			}
+			fmt.Fprintf(buf, "%s_bucket%s %d\n", fullName, metric.getLabelStr(label, formatMetricValue(bound)), count)
+		}
+		fmt.Fprintf(buf, "%s_bucket%s %d\n", fullName, metric.getLabelStr(label, "+Inf"), metric.counts[label])
+		fmt.Fprintf(buf, "%s_sum%s %s\n", fullName, metric.getLabelStr(label, ""), formatMetricValue(metric.values[label]))
+		fmt.Fprintf(buf, "%s_count%s %d\n", fullName, metric.getLabelStr(label, ""), metric.counts[label])
+	}
+}
+
+// @le: bucket bound of histogram, empty if not bucket
+func (metric *Metric) getLabelStr(label, le string) string {
+	var pairs []string
+	if metric.labelName != "" {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, metric.labelName, metricLabelReplacer.Replace(label)))
+	}
+	if le != "" {
+		pairs = append(pairs, fmt.Sprintf(`le="%s"`, le))
+	}
+
+	if len(pairs) == 0 {
+		return ""
+	}
+
+	s := "{" + pairs[0]
+	for _, pair := range pairs[1:] {
+		s += "," + pair
+	}
+	return s + "}"
+}
+
+// escaping of Prometheus text format, other characters like UTF-8 are written as they are
+var metricHelpReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+var metricLabelReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+func formatMetricValue(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
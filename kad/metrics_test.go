@@ -0,0 +1,51 @@
+package kad
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+)
+
+func TestWritePrometheus(t *testing.T) {
+	m := Metrics{metricMap: make(map[string]*Metric)}
+	m.register("requests_total", metricCounter, "path", "Requests by path,\nwith \\ in help.", nil)
+	m.register("contacts", metricGauge, "", "Contacts.", nil)
+	m.register("empty_total", metricCounter, "opcode", "Labelled metric without samples.", nil)
+	m.register("duration_seconds", metricHistogram, "", "Duration.", []float64{0.5, 1, 2.5})
+	m.register("results", metricHistogram, "type", "Results by type.", []float64{0, 10})
+
+	m.inc("requests_total", "/search")
+	m.add("requests_total", "/search", 2)
+	m.inc("requests_total", `a"b\c`+"\nd")
+	m.inc("requests_total", "中文\t")
+	m.set("contacts", "", 1234)
+	m.set("contacts", "", 1e21)
+
+	for _, v := range []float64{0.1, 0.5, 0.75, 3} {
+		m.observe("duration_seconds", "", v)
+	}
+	m.observe("results", "keyword", 0)
+	m.observe("results", "keyword", 25)
+
+	var buf bytes.Buffer
+	if err := m.WritePrometheus(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	golden, err := ioutil.ReadFile("testdata/metrics.prom")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if buf.String() != string(golden) {
+		t.Errorf("WritePrometheus got:\n%s\nwant:\n%s", buf.String(), golden)
+	}
+}
+
+// nil metrics is no-op for components without metrics.
+func TestNilMetrics(t *testing.T) {
+	var m *Metrics
+	m.inc(metricSearches, "keyword")
+	m.set(metricContacts, "", 1)
+	m.observe(metricSearchDuration, "", 1)
+}
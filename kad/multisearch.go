@@ -0,0 +1,89 @@
+package kad
+
+import (
+	"hahajing/com"
+	"sort"
+)
+
+// MultiSearch is combining keyword searches of one keyword group by file hash.
+type MultiSearch struct {
+	combine byte
+	resCh   chan *SearchRes
+
+	keywordNbr int // keyword searches in group
+	pendingNbr int // keyword searches not finished
+
+	files    map[[16]byte]*multiSearchFile
+	fileList []*multiSearchFile // in order of found
+}
+
+type multiSearchFile struct {
+	pFileLink *com.Ed2kFileLink
+	searchNos map[uint64]bool // keyword searches returned this file
+}
+
+func newMultiSearch(combine byte, resCh chan *SearchRes, keywordNbr int) *MultiSearch {
+	return &MultiSearch{
+		combine:    combine,
+		resCh:      resCh,
+		keywordNbr: keywordNbr,
+		pendingNbr: keywordNbr,
+		files:      make(map[[16]byte]*multiSearchFile)}
+}
+
+func (ms *MultiSearch) addFileLinks(no uint64, fileLinks []*com.Ed2kFileLink) {
+	for _, fileLink := range fileLinks {
+		hash := fileLink.GetHash()
+
+		file := ms.files[hash]
+		if file == nil {
+			file = &multiSearchFile{pFileLink: fileLink, searchNos: make(map[uint64]bool)}
+			ms.files[hash] = file
+			ms.fileList = append(ms.fileList, file)
+		} else if fileLink.FakeScore != file.pFileLink.FakeScore { // rescored by more names
+			file.pFileLink = fileLink
+		}
+
+		file.searchNos[no] = true
+	}
+}
+
+// finishSearch is called when one keyword search expires, results are sent after all finished.
+func (ms *MultiSearch) finishSearch() {
+	ms.pendingNbr--
+	if ms.pendingNbr > 0 {
+		return
+	}
+
+	fileLinks := ms.getFileLinks()
+	if fileLinks != nil && len(ms.resCh) < cap(ms.resCh) {
+		ms.resCh <- &SearchRes{FileLinks: fileLinks}
+	}
+}
+
+// get ranked file links, more hits first, then more sources
+func (ms *MultiSearch) getFileLinks() []*com.Ed2kFileLink {
+	var files []*multiSearchFile
+	for _, file := range ms.fileList {
+		if ms.combine == SearchCombineIntersect && len(file.searchNos) < ms.keywordNbr {
+			continue
+		}
+
+		files = append(files, file)
+	}
+
+	sort.SliceStable(files, func(i, j int) bool {
+		if len(files[i].searchNos) != len(files[j].searchNos) {
+			return len(files[i].searchNos) > len(files[j].searchNos)
+		}
+		return files[i].pFileLink.Avail > files[j].pFileLink.Avail
+	})
+
+	var fileLinks []*com.Ed2kFileLink
+	for _, file := range files {
+		file.pFileLink.KeywordHits = len(file.searchNos)
+		fileLinks = append(fileLinks, file.pFileLink)
+	}
+
+	return fileLinks
+}
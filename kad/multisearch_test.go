@@ -0,0 +1,92 @@
+package kad
+
+import (
+	"hahajing/com"
+	"testing"
+)
+
+// runMultiSearch is feeding files to keyword searches of one group, and returns what's sent to user.
+func runMultiSearch(t *testing.T, combine byte, filesList [][]*Ed2kFileStruct) []*com.Ed2kFileLink {
+	resCh := make(chan *SearchRes, SearchResChSize)
+	pMulti := newMultiSearch(combine, resCh, len(filesList))
+	myKeywordStruct := com.MyKeywordStruct{Items: []*com.Item{{Type: com.Movie, OrgName: "Inception", Year: 2010}}}
+
+	var searches []*Search
+	for i, files := range filesList {
+		pSearch := &Search{no: uint64(i), resCh: resCh, myKeywordStruct: &myKeywordStruct, pMulti: pMulti, bContentFilter: true,
+			fileHashMap: make(map[[16]byte]*Ed2kFileStruct)}
+		newFiles, _ := pSearch.addFiles(files, NewMetrics())
+		pSearch.sendFiles(newFiles)
+		searches = append(searches, pSearch)
+	}
+
+	// nothing is sent until all finished
+	for i, pSearch := range searches {
+		if len(resCh) != 0 {
+			t.Fatalf("%d: sent before all searches finished", i)
+		}
+		pSearch.pMulti.finishSearch()
+	}
+	if pMulti.pendingNbr != 0 || len(resCh) != 1 {
+		t.Fatalf("%d pending, %d results", pMulti.pendingNbr, len(resCh))
+	}
+
+	return (<-resCh).FileLinks
+}
+
+func newMultiSearchTestFiles(hashes []byte, avails []uint32) []*Ed2kFileStruct {
+	var files []*Ed2kFileStruct
+	for i, hash := range hashes {
+		files = append(files, &Ed2kFileStruct{Hash: [16]byte{hash}, Name: "Inception.2010.1080p.mkv", Size: 700 << 20,
+			Type: com.Ed2kFileTypeVideo, Avail: avails[i]})
+	}
+
+	return files
+}
+
+func TestMultiSearch(t *testing.T) {
+	// 2 is found by both, then more sources first
+	getFilesList := func() [][]*Ed2kFileStruct {
+		return [][]*Ed2kFileStruct{
+			newMultiSearchTestFiles([]byte{1, 2}, []uint32{5, 1}),
+			newMultiSearchTestFiles([]byte{2, 3}, []uint32{1, 9}),
+		}
+	}
+
+	tests := []struct {
+		combine    byte
+		wantHashes []byte
+		wantHits   []int
+	}{
+		{SearchCombineUnion, []byte{2, 3, 1}, []int{2, 1, 1}},
+		{SearchCombineIntersect, []byte{2}, []int{2}},
+	}
+
+	for _, test := range tests {
+		fileLinks := runMultiSearch(t, test.combine, getFilesList())
+		if len(fileLinks) != len(test.wantHashes) {
+			t.Errorf("combine %d: %d files, want %d", test.combine, len(fileLinks), len(test.wantHashes))
+			continue
+		}
+
+		for i, fileLink := range fileLinks {
+			if fileLink.Hash[0] != test.wantHashes[i] || fileLink.KeywordHits != test.wantHits[i] {
+				t.Errorf("combine %d: %d is %d of %d hits, want %d of %d hits", test.combine, i, fileLink.Hash[0], fileLink.KeywordHits,
+					test.wantHashes[i], test.wantHits[i])
+			}
+		}
+	}
+
+	// nothing in common
+	filesList := [][]*Ed2kFileStruct{newMultiSearchTestFiles([]byte{1}, []uint32{1}), newMultiSearchTestFiles([]byte{2}, []uint32{1})}
+	resCh := make(chan *SearchRes, 1)
+	pMulti := newMultiSearch(SearchCombineIntersect, resCh, len(filesList))
+	for i, files := range filesList {
+		links := []*com.Ed2kFileLink{{Name: files[0].Name, Hash: files[0].Hash[:]}}
+		pMulti.addFileLinks(uint64(i), links)
+		pMulti.finishSearch()
+	}
+	if len(resCh) != 0 {
+		t.Errorf("intersect without common files is sent")
+	}
+}
@@ -22,6 +22,8 @@ type Packet struct {
 	// Destination will fill it as receiver verify key so that I can verify it's destination I sent before.
 	// For what meaning of receiver verify key or sender verify key, pay attention to the direction of the packet.
 	senderVerifyKey uint32
+
+	priority byte // for sending, see Shaper
 }
 
 // For send
@@ -8,20 +8,20 @@ import (
 // It will generate and send packet(for sending), filter and dispatch packet(for receiving).
 type PacketProcessor struct {
 	pPrefs          *Prefs
+	pMetrics        *Metrics
 	pContactManager *ContactManager
 	pSearchManager  *SearchManager
 	pPacketReqGuard *PacketReqGuard
-
-	sendCh chan *Packet
+	pShaper         *Shaper
 }
 
-func (pp *PacketProcessor) start(pPrefs *Prefs, pContactManager *ContactManager, pSearchManager *SearchManager, pPacketReqGuard *PacketReqGuard, sendCh chan *Packet) {
+func (pp *PacketProcessor) start(pPrefs *Prefs, pMetrics *Metrics, pContactManager *ContactManager, pSearchManager *SearchManager, pPacketReqGuard *PacketReqGuard, pShaper *Shaper) {
 	pp.pPrefs = pPrefs
+	pp.pMetrics = pMetrics
 	pp.pContactManager = pContactManager
 	pp.pSearchManager = pSearchManager
 	pp.pPacketReqGuard = pPacketReqGuard
-
-	pp.sendCh = sendCh
+	pp.pShaper = pShaper
 }
 
 func (pp *PacketProcessor) sendMyDetails(opcode byte, pContact *Contact) {
@@ -50,12 +50,12 @@ func (pp *PacketProcessor) sendMyDetails(opcode byte, pContact *Contact) {
 		pContact = &contact
 	}
 
-	pp.sendPacket(opcode, pContact, bi.getBuf())
+	pp.sendPacket(opcode, packetPriorityHello, pContact, bi.getBuf())
 }
 
-func (pp *PacketProcessor) sendPacket(opcode byte, pContact *Contact, buf []byte) {
+func (pp *PacketProcessor) sendPacket(opcode, priority byte, pContact *Contact, buf []byte) {
 	// can we pass from guard?
-	if !pp.pPacketReqGuard.add(time.Now().Unix(), pContact.ip, opcode) {
+	if !pp.pPacketReqGuard.add(time.Now().Unix(), pContact.ip, opcode, pContact.getVersion()) {
 		//com.HhjLog.Warningf("Sending %s to %s:%d, %s isn't passed by PacketReqGuard\n", getOpcodeStr(opcode), iIP2Str(pContact.ip), pContact.updPort, getVersionStr(pContact.getVersion()))
 		return
 	}
@@ -81,13 +81,16 @@ func (pp *PacketProcessor) sendPacket(opcode byte, pContact *Contact, buf []byte
 		buf:               buf,
 		receiverVerifyKey: receiverVerifyKey,
 		senderVerifyKey:   senderVerifyKey,
+		priority:          priority,
 	}
 
-	// send to socket
-	pp.sendCh <- &packet
+	// send to socket via shaper
+	pp.pShaper.push(&packet)
 }
 
 func (pp *PacketProcessor) processPacket(pPacket *Packet) {
+	pp.pPacketReqGuard.addRes(pPacket.ip)
+
 	switch pPacket.opcode {
 	case kademlia2HelloRes:
 		pp.processKademlia2HelloRes(pPacket)
@@ -100,7 +103,10 @@ func (pp *PacketProcessor) processPacket(pPacket *Packet) {
 
 func (pp *PacketProcessor) processKademlia2HelloRes(pPacket *Packet) {
 	msg := Kademlia2HelloResMsg{}
-	msg.set(pPacket)
+	if !msg.set(pPacket) {
+		pp.pMetrics.inc(metricMessagesInvalid, getOpcodeStr(pPacket.opcode))
+		return
+	}
 
 	pp.pContactManager.addKademlia2HelloRes(&msg)
 }
@@ -112,6 +118,8 @@ func (pp *PacketProcessor) processKademlia2Res(pPacket *Packet) {
 			// It's not our response for search key
 			pp.pContactManager.addKademlia2Res(&msg)
 		}
+	} else {
+		pp.pMetrics.inc(metricMessagesInvalid, getOpcodeStr(pPacket.opcode))
 	}
 }
 
@@ -119,10 +127,12 @@ func (pp *PacketProcessor) processKademlia2SearchRes(pPacket *Packet) {
 	msg := Kademlia2SearchResMsg{}
 	if msg.set(pPacket) {
 		pp.pSearchManager.addKademlia2SearchRes(&msg)
+	} else {
+		pp.pMetrics.inc(metricMessagesInvalid, getOpcodeStr(pPacket.opcode))
 	}
 }
 
-func (pp *PacketProcessor) sendFindValue(pContact *Contact, pTargetID *ID) {
+func (pp *PacketProcessor) sendFindValue(pContact *Contact, pTargetID *ID, priority byte) {
 	bi := ByteIO{buf: make([]byte, 33)}
 
 	// how many contacts we wanted
@@ -149,7 +159,7 @@ func (pp *PacketProcessor) sendFindValue(pContact *Contact, pTargetID *ID) {
 		pContact = &contact
 	}
 
-	pp.sendPacket(kademlia2Req, pContact, bi.getBuf())
+	pp.sendPacket(kademlia2Req, priority, pContact, bi.getBuf())
 }
 
 func (pp *PacketProcessor) sendSearchKeyword(pContact *Contact, targetHash []byte) {
@@ -174,5 +184,56 @@ func (pp *PacketProcessor) sendSearchKeyword(pContact *Contact, targetHash []byt
 		}
 	}
 
-	pp.sendPacket(kademlia2SearchKeyReq, pContact, bi.getBuf())
+	pp.sendPacket(kademlia2SearchKeyReq, packetPrioritySearch, pContact, bi.getBuf())
+}
+
+func (pp *PacketProcessor) sendSearchSource(pContact *Contact, targetHash []byte, fileSize uint64) {
+	bi := ByteIO{buf: make([]byte, 26)}
+
+	// target hash
+	bi.writeBytes(targetHash)
+
+	bi.writeUint16(uint16(0)) // start position
+	bi.writeUint64(fileSize)
+
+	version := pContact.getVersion()
+	if version < kademliaVersion6_49aBeta {
+		// low version not support encrytion
+		contact := *pContact
+		contact.pKadID = nil
+		contact.resetUDPKey()
+
+		pContact = &contact
+
+		if version < kademliaVersion3_47b {
+			return
+		}
+	}
+
+	pp.sendPacket(kademlia2SearchSourceReq, packetPrioritySearch, pContact, bi.getBuf())
+}
+
+func (pp *PacketProcessor) sendSearchNotes(pContact *Contact, targetHash []byte, fileSize uint64) {
+	bi := ByteIO{buf: make([]byte, 24)}
+
+	// target hash
+	bi.writeBytes(targetHash)
+
+	bi.writeUint64(fileSize)
+
+	version := pContact.getVersion()
+	if version < kademliaVersion6_49aBeta {
+		// low version not support encrytion
+		contact := *pContact
+		contact.pKadID = nil
+		contact.resetUDPKey()
+
+		pContact = &contact
+
+		if version < kademliaVersion3_47b {
+			return
+		}
+	}
+
+	pp.sendPacket(kademlia2SearchNotesReq, packetPrioritySearch, pContact, bi.getBuf())
 }
@@ -6,20 +6,64 @@ import (
 
 const packetReqLimitTime = 60 // second
 
-// limits of Kademlia requests per time interval
+// Flood protection of eMule(CPacketTracking::InTrackListIsAllowedPacket) was introduced in 0.49a.
+// Requests over limit per minute are dropped silently, and IP is banned if it's far over limit.
+// Older clients have no flood protection at all, the looser limit for them is ours, not from eMule.
+const packetReqLegacyFactor = 2
+
+// limits of Kademlia requests per time interval, same as eMule 0.50a
 var packetReqLimits = map[byte]int{
-	kademlia2HelloReq:     3,
-	kademlia2Req:          10,
-	kademlia2SearchKeyReq: 3}
+	kademlia2HelloReq:        3,
+	kademlia2Req:             10,
+	kademlia2SearchKeyReq:    3,
+	kademlia2SearchSourceReq: 3,
+	kademlia2SearchNotesReq:  3}
+
+// Requests which are always answered by eMule, so no answer means remote is dropping us or offline.
+// Search requests are not here because there is no answer if nothing is found.
+var packetReqWithRes = map[byte]bool{
+	kademlia2HelloReq: true,
+	kademlia2Req:      true}
+
+const (
+	packetResTimeout          = 10  // second, request is missed if no answer after this
+	packetMissesBeforeBackoff = 2   // consecutive missed requests before backing off
+	packetBackoffTime         = 30  // second, doubled for each more missed request
+	packetBackoffMaxTime      = 600 // second
+	packetReqTrackTime        = 600 // second, keep health of IP after the last request
+
+	packetHealthMax  = 100
+	packetHealthInit = 50 // unknown IP
+)
+
+func getPacketReqLimit(opcode byte, version uint8) int {
+	limit := packetReqLimits[opcode]
+	if version < kademliaVersion7_49a {
+		limit *= packetReqLegacyFactor
+	}
+
+	return limit
+}
 
 // PacketReqPerIP is KAD requests counting per IP.
 type PacketReqPerIP struct {
 	reqs map[byte][]int64 // opcode: [time]
+
+	// for backing off
+	tPending        int64 // the first unanswered request time, 0 if nothing
+	misses          int   // consecutive missed requests
+	tBackoffExpires int64
+
+	health int // 0 - packetHealthMax, moving average of answered requests
+}
+
+func newPacketReqPerIP() *PacketReqPerIP {
+	return &PacketReqPerIP{reqs: make(map[byte][]int64), health: packetHealthInit}
 }
 
-func (p *PacketReqPerIP) canPass(t int64, opcode byte) (int, bool) {
+func (p *PacketReqPerIP) canPass(t int64, opcode byte, version uint8) (int, bool) {
 	times := p.reqs[opcode]
-	limit := packetReqLimits[opcode]
+	limit := getPacketReqLimit(opcode, version)
 	count := 1 // assume this one is added.
 	i := len(times) - 1
 	for ; i >= 0; i-- {
@@ -36,8 +80,8 @@ func (p *PacketReqPerIP) canPass(t int64, opcode byte) (int, bool) {
 	return i, true // -1: empty
 }
 
-func (p *PacketReqPerIP) add(t int64, opcode byte) bool {
-	i, pass := p.canPass(t, opcode)
+func (p *PacketReqPerIP) add(t int64, opcode byte, version uint8) bool {
+	i, pass := p.canPass(t, opcode, version)
 	if !pass {
 		return false
 	}
@@ -50,31 +94,87 @@ func (p *PacketReqPerIP) add(t int64, opcode byte) bool {
 	}
 	p.reqs[opcode] = times[i:]
 
+	if packetReqWithRes[opcode] && p.tPending == 0 {
+		p.tPending = t
+	}
+
+	return true
+}
+
+// check if pending request is missed, return true if it's backing off after this
+func (p *PacketReqPerIP) checkPending(t int64) bool {
+	if p.tPending == 0 || t-p.tPending <= packetResTimeout {
+		return false
+	}
+
+	p.tPending = 0
+	p.misses++
+	p.health -= (p.health + 3) / 4
+
+	if p.misses < packetMissesBeforeBackoff {
+		return false
+	}
+
+	backoff := int64(packetBackoffTime) << uint(p.misses-packetMissesBeforeBackoff)
+	if backoff > packetBackoffMaxTime || backoff <= 0 {
+		backoff = packetBackoffMaxTime
+	}
+	p.tBackoffExpires = t + backoff
+
 	return true
 }
 
+func (p *PacketReqPerIP) addRes() {
+	p.tPending = 0
+	p.misses = 0
+	p.tBackoffExpires = 0
+	p.health += (packetHealthMax - p.health + 3) / 4
+}
+
+func (p *PacketReqPerIP) isBackingOff(t int64) bool {
+	return t < p.tBackoffExpires
+}
+
 // PacketReqGuard is guard for monitoring each KAD request for each remote IP so that remote KAD client will not drop our packets.
+// It also backs off from IPs which stop answering, and scores health of each IP for search decision.
 // Here IPs are not synchronized with these in ContactManager. They're independ.
 type PacketReqGuard struct {
 	reqs map[uint32]*PacketReqPerIP // remote IP: *PacketReqPerIP
 
 	curTime     int64
-	trackReqs   map[uint32]int64          // remote IP: request time
+	trackReqs   map[uint32]int64          // remote IP: expires time
 	expiresReqs map[int64]map[uint32]bool // time: remote IP
+
+	pMetrics *Metrics
 }
 
-func (g *PacketReqGuard) start() {
+func (g *PacketReqGuard) start(pMetrics *Metrics) {
+	g.pMetrics = pMetrics
+
 	g.reqs = make(map[uint32]*PacketReqPerIP)
 
 	g.trackReqs = make(map[uint32]int64)
 	g.expiresReqs = make(map[int64]map[uint32]bool)
 }
 
-func (g *PacketReqGuard) add(t int64, remoteIP uint32, opcode byte) bool {
+// get request counting of IP and check its pending request
+func (g *PacketReqGuard) get(t int64, remoteIP uint32) *PacketReqPerIP {
+	reqs := g.reqs[remoteIP]
+	if reqs == nil {
+		return nil
+	}
+
+	if reqs.checkPending(t) {
+		g.pMetrics.inc(metricGuardBackoffs, "")
+		g.track(remoteIP, reqs.tBackoffExpires)
+	}
+
+	return reqs
+}
+
+func (g *PacketReqGuard) add(t int64, remoteIP uint32, opcode byte, version uint8) bool {
 	// we only care about requests
-	if opcode != kademlia2HelloReq &&
-		opcode != kademlia2Req &&
-		opcode != kademlia2SearchKeyReq {
+	if _, ok := packetReqLimits[opcode]; !ok {
 		return true
 	}
 
@@ -82,28 +182,51 @@ func (g *PacketReqGuard) add(t int64, remoteIP uint32, opcode byte) bool {
 		g.curTime = t + 1
 	}
 
-	reqs := g.reqs[remoteIP]
+	reqs := g.get(t, remoteIP)
 	if reqs == nil {
-		reqs = &PacketReqPerIP{reqs: make(map[byte][]int64)}
+		reqs = newPacketReqPerIP()
 		g.reqs[remoteIP] = reqs
 	}
 
-	if !reqs.add(t, opcode) {
+	if reqs.isBackingOff(t) || !reqs.add(t, opcode, version) {
+		g.pMetrics.inc(metricGuardRejections, getOpcodeStr(opcode))
 		return false
 	}
 
+	g.track(remoteIP, t+packetReqTrackTime)
+
+	g.pMetrics.set(metricGuardTrackedIPs, "", float64(len(g.reqs)))
+
+	return true
+}
+
+// addRes is called for any packet received from remote IP, which means it's not dropping us.
+func (g *PacketReqGuard) addRes(remoteIP uint32) {
+	reqs := g.reqs[remoteIP]
+	if reqs == nil {
+		return
+	}
+
+	reqs.addRes()
+}
+
+// track IP until @expiresTime, it's never shortened
+func (g *PacketReqGuard) track(remoteIP uint32, expiresTime int64) {
 	// remove
-	expiresTime, ok := g.trackReqs[remoteIP]
+	oldExpiresTime, ok := g.trackReqs[remoteIP]
 	if ok {
-		ips := g.expiresReqs[expiresTime]
+		if oldExpiresTime >= expiresTime {
+			return
+		}
+
+		ips := g.expiresReqs[oldExpiresTime]
 		delete(ips, remoteIP)
 		if len(ips) == 0 {
-			delete(g.expiresReqs, expiresTime)
+			delete(g.expiresReqs, oldExpiresTime)
 		}
 	}
 
 	// add
-	expiresTime = t + packetReqLimitTime
 	g.trackReqs[remoteIP] = expiresTime
 
 	ips := g.expiresReqs[expiresTime]
@@ -112,8 +235,6 @@ func (g *PacketReqGuard) add(t int64, remoteIP uint32, opcode byte) bool {
 		g.expiresReqs[expiresTime] = ips
 	}
 	ips[remoteIP] = true
-
-	return true
 }
 
 func (g *PacketReqGuard) timerProcess() {
@@ -132,14 +253,34 @@ func (g *PacketReqGuard) timerProcess() {
 
 		delete(g.expiresReqs, g.curTime)
 	}
+
+	g.pMetrics.set(metricGuardTrackedIPs, "", float64(len(g.reqs)))
 }
 
-func (g *PacketReqGuard) canPass(t int64, remoteIP uint32, opcode byte) bool {
-	reqs := g.reqs[remoteIP]
+func (g *PacketReqGuard) canPass(t int64, remoteIP uint32, opcode byte, version uint8) bool {
+	reqs := g.get(t, remoteIP)
 	if reqs == nil {
 		return true
 	}
 
-	_, pass := reqs.canPass(t, opcode)
+	if reqs.isBackingOff(t) {
+		return false
+	}
+
+	_, pass := reqs.canPass(t, opcode, version)
 	return pass
 }
+
+// getHealth returns health score of IP, 0 is dead or backing off, packetHealthMax is always answering.
+func (g *PacketReqGuard) getHealth(t int64, remoteIP uint32) int {
+	reqs := g.get(t, remoteIP)
+	if reqs == nil {
+		return packetHealthInit
+	}
+
+	if reqs.isBackingOff(t) {
+		return 0
+	}
+
+	return reqs.health
+}
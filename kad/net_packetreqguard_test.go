@@ -0,0 +1,144 @@
+package kad
+
+import (
+	"testing"
+)
+
+func TestPacketReqLimit(t *testing.T) {
+	p := newPacketReqPerIP()
+	for i := 0; i < packetReqLimits[kademlia2Req]; i++ {
+		if !p.add(1000, kademlia2Req, kademliaVersion8_49b) {
+			t.Fatalf("request %d is rejected", i)
+		}
+	}
+	if p.add(1000, kademlia2Req, kademliaVersion8_49b) {
+		t.Error("request over limit is passed")
+	}
+
+	// other opcodes have their own limits, old requests expire
+	if !p.add(1000, kademlia2SearchKeyReq, kademliaVersion8_49b) {
+		t.Error("search request is rejected")
+	}
+	if !p.add(1000+packetReqLimitTime+1, kademlia2Req, kademliaVersion8_49b) {
+		t.Error("request after limit time is rejected")
+	}
+
+	// looser limit for clients without flood protection
+	if limit := getPacketReqLimit(kademlia2Req, kademliaVersion5_48a); limit != packetReqLimits[kademlia2Req]*packetReqLegacyFactor {
+		t.Errorf("legacy limit = %d", limit)
+	}
+}
+
+func TestPacketReqBackoff(t *testing.T) {
+	p := newPacketReqPerIP()
+	t0 := int64(1000)
+
+	// the first missed request doesn't back off
+	p.add(t0, kademlia2Req, kademliaVersion8_49b)
+	if p.checkPending(t0+packetResTimeout) || p.misses != 0 {
+		t.Fatalf("pending request is missed before timeout")
+	}
+	if p.checkPending(t0+packetResTimeout+1) || p.misses != 1 || p.isBackingOff(t0+packetResTimeout+1) {
+		t.Fatalf("backing off after %d misses", p.misses)
+	}
+
+	// backoff is doubled for each more missed request, until max
+	expected := []int64{30, 60, 120, 240, 480, 600, 600}
+	for i, backoff := range expected {
+		t1 := t0 + int64(i+1)*1000
+		p.add(t1, kademlia2Req, kademliaVersion8_49b)
+		if !p.checkPending(t1 + packetResTimeout + 1) {
+			t.Fatalf("%d: not backing off", i)
+		}
+
+		tMissed := t1 + packetResTimeout + 1
+		if p.tBackoffExpires-tMissed != backoff || !p.isBackingOff(tMissed+backoff-1) || p.isBackingOff(tMissed+backoff) {
+			t.Errorf("%d: backoff %d, expected %d", i, p.tBackoffExpires-tMissed, backoff)
+		}
+	}
+
+	// requests without answer are not pending
+	p.add(t0+10000, kademlia2SearchKeyReq, kademliaVersion8_49b)
+	if p.tPending != 0 {
+		t.Errorf("search request is pending")
+	}
+
+	// recovered by any answer
+	health := p.health
+	p.addRes()
+	if p.misses != 0 || p.isBackingOff(t0) || p.health <= health {
+		t.Errorf("not recovered: %d misses, health %d -> %d", p.misses, health, p.health)
+	}
+}
+
+func TestPacketReqGuardHealth(t *testing.T) {
+	var g PacketReqGuard
+	g.start(NewMetrics())
+	t0 := int64(1000)
+
+	if health := g.getHealth(t0, 1); health != packetHealthInit {
+		t.Errorf("unknown IP health = %d", health)
+	}
+
+	// always answering is up to max
+	for i := int64(0); i < 20; i++ {
+		g.add(t0+i*100, 1, kademlia2Req, kademliaVersion8_49b)
+		g.addRes(1)
+	}
+	if health := g.getHealth(t0+2000, 1); health != packetHealthMax {
+		t.Errorf("answering IP health = %d", health)
+	}
+
+	// missing is down, and 0 while backing off
+	g.add(t0, 2, kademlia2Req, kademliaVersion8_49b)
+	if health := g.getHealth(t0+packetResTimeout+1, 2); health >= packetHealthInit || health == 0 {
+		t.Errorf("missing IP health = %d", health)
+	}
+	g.add(t0+100, 2, kademlia2Req, kademliaVersion8_49b)
+	if health := g.getHealth(t0+100+packetResTimeout+1, 2); health != 0 {
+		t.Errorf("backing off IP health = %d", health)
+	}
+	if g.canPass(t0+100+packetResTimeout+2, 2, kademlia2Req, kademliaVersion8_49b) ||
+		g.add(t0+100+packetResTimeout+2, 2, kademlia2Req, kademliaVersion8_49b) {
+		t.Errorf("request to backing off IP is passed")
+	}
+}
+
+func TestGetHealthyContacts(t *testing.T) {
+	var g PacketReqGuard
+	g.start(NewMetrics())
+	t0 := int64(1000)
+
+	// 1: unknown, 2: healthy, 3: backing off, 4: unhealthy
+	g.add(t0, 2, kademlia2Req, kademliaVersion8_49b)
+	g.addRes(2)
+	g.add(t0, 3, kademlia2Req, kademliaVersion8_49b)
+	g.reqs[3].misses = packetMissesBeforeBackoff
+	g.add(t0, 4, kademlia2Req, kademliaVersion8_49b)
+	g.addRes(4)
+	g.reqs[4].health = searchMinHealth - 1
+
+	var candidates []*Contact
+	for ip := uint32(1); ip <= 4; ip++ {
+		candidates = append(candidates, &Contact{ip: ip})
+	}
+
+	contacts := getHealthyContacts(t0+packetResTimeout+1, candidates, &g)
+	if len(contacts) != 2 || contacts[0].ip != 2 || contacts[1].ip != 1 {
+		var ips []uint32
+		for _, pContact := range contacts {
+			ips = append(ips, pContact.ip)
+		}
+		t.Fatalf("contacts = %v, expected [2 1]", ips)
+	}
+
+	// at most bootstrapSearchContactNbr, in order of candidates for same health
+	candidates = nil
+	for ip := uint32(100); ip < 100+bootstrapSearchContactNbr*searchCandidateFactor; ip++ {
+		candidates = append(candidates, &Contact{ip: ip})
+	}
+	contacts = getHealthyContacts(t0, candidates, &g)
+	if len(contacts) != bootstrapSearchContactNbr || contacts[0].ip != 100 || contacts[bootstrapSearchContactNbr-1].ip != 100+bootstrapSearchContactNbr-1 {
+		t.Errorf("%d contacts from %d", len(contacts), contacts[0].ip)
+	}
+}
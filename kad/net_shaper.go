@@ -0,0 +1,223 @@
+package kad
+
+import (
+	"encoding/json"
+	"hahajing/com"
+	"io/ioutil"
+	"os"
+	"time"
+)
+
+// priorities of sending packets, smaller is higher
+const (
+	packetPrioritySearch  byte = 0
+	packetPriorityHello   byte = 1
+	packetPriorityFinding byte = 2 // finding contacts for routing table
+
+	packetPriorityNbr = 3
+)
+
+var packetPriorityStrs = [packetPriorityNbr]string{"search", "hello", "finding"}
+
+const udpHeaderSize = 8 // counted in bytes of each packet
+
+// ShaperConfig is limits of all outbound KAD traffic. 0 means unlimited.
+type ShaperConfig struct {
+	BytesPerSecond   int
+	PacketsPerSecond int
+	BurstSeconds     float64 // size of bucket in seconds of rate
+	QueueSize        int     // for each priority, packets are dropped if queue is full
+}
+
+// DefaultShaperConfig x
+var DefaultShaperConfig = ShaperConfig{
+	BytesPerSecond:   256 * 1024,
+	PacketsPerSecond: 500,
+	BurstSeconds:     1,
+	QueueSize:        bootstrapSearchContactNbr * int(kademliaFindNode) * int(kademliaFindNode)}
+
+func getShaperConfigFileName() string {
+	path := com.GetConfigPath()
+	return path + "/config/kad/shaper.json"
+}
+
+// LoadShaperConfig is loading shaper config from JSON file, missing fields are default.
+func LoadShaperConfig(fileName string) (*ShaperConfig, error) {
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	config := DefaultShaperConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+// TokenBucket x
+// Tokens can be borrowed, so that taking returns how long to wait before tokens are really available.
+type TokenBucket struct {
+	rate   float64 // tokens per second, 0 means unlimited
+	burst  float64
+	tokens float64
+	tLast  time.Time
+}
+
+func (b *TokenBucket) start(rate int, burstSeconds float64, t time.Time) {
+	b.rate = float64(rate)
+	b.burst = b.rate * burstSeconds
+	if b.burst < 1 {
+		b.burst = 1
+	}
+	b.tokens = b.burst
+	b.tLast = t
+}
+
+func (b *TokenBucket) take(t time.Time, n float64) time.Duration {
+	if b.rate <= 0 {
+		return 0
+	}
+
+	// refill
+	b.tokens += t.Sub(b.tLast).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.tLast = t
+
+	b.tokens -= n
+	if b.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// Shaper is shaping all sending packets before sockets by token buckets of bytes and packets.
+// Packets are queued by priority, KAD routine pushes packets and sending routine of SocketManager pops them.
+type Shaper struct {
+	config ShaperConfig
+
+	sendChs [packetPriorityNbr]chan *Packet
+
+	// only accessed by sending routine
+	bytes, packets TokenBucket
+
+	pMetrics *Metrics
+
+	now func() time.Time // time.Now, replaced in tests
+}
+
+func (sh *Shaper) start(config *ShaperConfig, pMetrics *Metrics) {
+	sh.config = *config
+	sh.pMetrics = pMetrics
+
+	if sh.config.QueueSize <= 0 {
+		sh.config.QueueSize = DefaultShaperConfig.QueueSize
+	}
+	for i := range sh.sendChs {
+		sh.sendChs[i] = make(chan *Packet, sh.config.QueueSize)
+	}
+
+	if sh.now == nil {
+		sh.now = time.Now
+	}
+
+	t := sh.now()
+	sh.bytes.start(sh.config.BytesPerSecond, sh.config.BurstSeconds, t)
+	sh.packets.start(sh.config.PacketsPerSecond, sh.config.BurstSeconds, t)
+
+	com.HhjLog.Infof("Shaper: %d bytes/s, %d packets/s", sh.config.BytesPerSecond, sh.config.PacketsPerSecond)
+}
+
+// push never blocks KAD routine, packet is dropped if its queue is full.
+func (sh *Shaper) push(pPacket *Packet) bool {
+	ch := sh.sendChs[pPacket.priority]
+	select {
+	case ch <- pPacket:
+	default:
+		sh.pMetrics.inc(metricPacketsDropped, "shaper_queue_full")
+		return false
+	}
+
+	sh.pMetrics.set(metricShaperQueued, packetPriorityStrs[pPacket.priority], float64(len(ch)))
+	return true
+}
+
+// pop is getting packet with the highest priority, nil if stopped.
+func (sh *Shaper) pop(stopCh chan bool) *Packet {
+	var pPacket *Packet
+
+	for _, ch := range sh.sendChs {
+		select {
+		case pPacket = <-ch:
+		default:
+		}
+
+		if pPacket != nil {
+			break
+		}
+	}
+
+	if pPacket == nil {
+		select {
+		case pPacket = <-sh.sendChs[packetPrioritySearch]:
+		case pPacket = <-sh.sendChs[packetPriorityHello]:
+		case pPacket = <-sh.sendChs[packetPriorityFinding]:
+		case <-stopCh:
+			return nil
+		}
+	}
+
+	label := packetPriorityStrs[pPacket.priority]
+	sh.pMetrics.set(metricShaperQueued, label, float64(len(sh.sendChs[pPacket.priority])))
+	sh.pMetrics.inc(metricShaperPackets, label)
+
+	return pPacket
+}
+
+// wait until packet can be sent, return false if stopped.
+func (sh *Shaper) wait(pPacket *Packet, stopCh chan bool) bool {
+	t := sh.now()
+	delay := sh.bytes.take(t, float64(len(pPacket.buf)+udpHeaderSize))
+	if d := sh.packets.take(t, 1); d > delay {
+		delay = d
+	}
+
+	if delay <= 0 {
+		return true
+	}
+
+	sh.pMetrics.add(metricShaperDelay, "", delay.Seconds())
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-stopCh:
+		return false
+	}
+}
+
+func (k *Kad) getShaperConfig() *ShaperConfig {
+	if k.ShaperConfig != nil {
+		return k.ShaperConfig
+	}
+
+	fileName := getShaperConfigFileName()
+	if _, err := os.Stat(fileName); err != nil {
+		return &DefaultShaperConfig
+	}
+
+	config, err := LoadShaperConfig(fileName)
+	if err != nil {
+		com.HhjLog.Criticalf("Load shaper config failed: %s", err)
+		return &DefaultShaperConfig
+	}
+
+	return config
+}
@@ -0,0 +1,76 @@
+package kad
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTokenBucket(t *testing.T) {
+	t0 := time.Unix(1000, 0)
+
+	var b TokenBucket
+	b.start(100, 2, t0) // 200 tokens at most
+
+	steps := []struct {
+		after time.Duration // since t0
+		n     float64
+		want  time.Duration
+	}{
+		{0, 150, 0},                                     // 50 left
+		{0, 100, 500 * time.Millisecond},                // borrowed 50
+		{time.Second, 50, 0},                            // refilled 100, 0 left
+		{10 * time.Second, 250, 500 * time.Millisecond}, // refilled to burst 200 only
+		{10 * time.Second, 100, 1500 * time.Millisecond},
+	}
+
+	for i, step := range steps {
+		if got := b.take(t0.Add(step.after), step.n); got != step.want {
+			t.Errorf("%d: take(%v, %v) = %v, want %v", i, step.after, step.n, got, step.want)
+		}
+	}
+
+	// unlimited
+	var unlimited TokenBucket
+	unlimited.start(0, 1, t0)
+	if got := unlimited.take(t0, 1e9); got != 0 {
+		t.Errorf("unlimited take = %v, want 0", got)
+	}
+
+	// burst is 1 token at least
+	var small TokenBucket
+	small.start(1, 0.1, t0)
+	if got := small.take(t0, 1); got != 0 {
+		t.Errorf("small take = %v, want 0", got)
+	}
+}
+
+func TestShaperWait(t *testing.T) {
+	now := time.Unix(1000, 0)
+	sh := Shaper{now: func() time.Time { return now }}
+	sh.start(&ShaperConfig{BytesPerSecond: 1000, PacketsPerSecond: 10, BurstSeconds: 1}, nil)
+
+	stopCh := make(chan bool)
+	close(stopCh) // wait returns false at once if there's delay
+
+	// UDP header is counted, 992 + 8 bytes use up the bucket
+	if !sh.wait(&Packet{buf: make([]byte, 1000-udpHeaderSize)}, stopCh) {
+		t.Fatal("the first packet should be sent at once")
+	}
+	if sh.bytes.tokens != 0 {
+		t.Errorf("bytes left %v, want 0", sh.bytes.tokens)
+	}
+	if sh.wait(&Packet{buf: make([]byte, 1)}, stopCh) {
+		t.Error("bytes should be limited")
+	}
+
+	// packets are limited even if bytes are enough
+	now = now.Add(time.Hour)
+	for i := 0; i < 10; i++ {
+		if !sh.wait(&Packet{}, stopCh) {
+			t.Fatalf("packet %d should be sent at once", i)
+		}
+	}
+	if sh.wait(&Packet{}, stopCh) {
+		t.Error("packets should be limited")
+	}
+}
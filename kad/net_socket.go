@@ -3,8 +3,10 @@ package kad
 import (
 	"crypto/rc4"
 	"encoding/binary"
+	"errors"
 	"hahajing/com"
 	"net"
+	"sync"
 )
 
 const (
@@ -19,26 +21,38 @@ type Socket struct {
 	conn           *net.UDPConn
 	recvCh, sendCh chan *Packet
 
-	pPrefs *Prefs
+	stopCh chan bool // closed for stopping routines
+	wg     *sync.WaitGroup
+
+	pPrefs   *Prefs
+	pMetrics *Metrics
 }
 
-func (s *Socket) start(pPrefs *Prefs, recvCh, sendCh chan *Packet, udpPort uint16) bool {
+func (s *Socket) start(pPrefs *Prefs, pMetrics *Metrics, recvCh, sendCh chan *Packet, udpPort uint16, stopCh chan bool, wg *sync.WaitGroup) error {
 	s.pPrefs = pPrefs
+	s.pMetrics = pMetrics
 	s.recvCh, s.sendCh = recvCh, sendCh
+	s.stopCh, s.wg = stopCh, wg
 
 	// init a UDP socket
 	var err error
 	s.conn, err = net.ListenUDP("udp", &net.UDPAddr{IP: nil, Port: int(udpPort)})
 	if err != nil {
 		com.HhjLog.Criticalf("Socket: Listen on UDP error: %s", err)
-		return false
+		return err
 	}
 
 	// loop to send and receive
+	s.wg.Add(2)
 	go s.recvRoutine()
 	go s.sendRoutine()
 
-	return true
+	return nil
+}
+
+// Routines will exit after stop channel closed, receiving routine exits after connection closed.
+func (s *Socket) close() {
+	s.conn.Close()
 }
 
 func (s *Socket) send(pPacket *Packet) {
@@ -51,28 +65,40 @@ func (s *Socket) send(pPacket *Packet) {
 	remoteAddr := &net.UDPAddr{IP: i2IP(pPacket.ip), Port: int(pPacket.port)}
 	_, err := s.conn.WriteToUDP(sendbuffer, remoteAddr)
 	if err != nil {
+		if errors.Is(err, net.ErrClosed) { // stopping
+			return
+		}
+
+		s.pMetrics.inc(metricPacketsDropped, "send_error")
 		com.HhjLog.Errorf("Socket: Write to UDP %s:%d error: %s\n", iIP2Str(pPacket.ip), pPacket.port, err)
 		return
 	}
 
+	s.pMetrics.inc(metricPacketsSent, getOpcodeStr(pPacket.opcode))
+	s.pMetrics.add(metricBytesSent, "", float64(len(sendbuffer)))
+
 	socketLog("Socket: Send packet to %s:%d\n", iIP2Str(pPacket.ip), pPacket.port)
 }
 
-func (s *Socket) recv() {
+// return false if connection closed
+func (s *Socket) recv() bool {
 	buf := make([]byte, 5000)
 	n, remoteAddr, err := s.conn.ReadFromUDP(buf)
 	if err != nil {
 		//com.HhjLog.Warningf("Socket: Read from UDP error: %s\n", err)
-		return
+		return !errors.Is(err, net.ErrClosed)
 	}
 
 	remoteIP := ip2I(remoteAddr.IP)
 	remotePort := uint16(remoteAddr.Port)
 
+	s.pMetrics.add(metricBytesReceived, "", float64(n))
+
 	buf, nReceiverVerifyKey, nSenderVerifyKey, bEncrypt := s.decrypt(buf[:n], remoteIP)
 	if buf == nil {
+		s.pMetrics.inc(metricPacketsDropped, "decrypt")
 		//com.HhjLog.Warningf("Socket: Decrypt received packet from %s:%d failed\n", iIP2Str(remoteIP), remotePort)
-		return
+		return true
 	}
 
 	// new packet
@@ -83,16 +109,24 @@ func (s *Socket) recv() {
 		senderVerifyKey:   nSenderVerifyKey}
 
 	if !packet.setBuf(buf) {
-		return
+		s.pMetrics.inc(metricPacketsDropped, "unsupported")
+		return true
 	}
 
+	s.pMetrics.inc(metricPacketsReceived, getOpcodeStr(packet.opcode))
+
 	if bEncrypt {
 		socketLog("Socket: Receive encrypt packet from %s:%d\n", iIP2Str(remoteIP), remotePort)
 	} else {
 		socketLog("Socket: Receive non-encrypt packet from %s:%d\n", iIP2Str(remoteIP), remotePort)
 	}
 
-	s.recvCh <- &packet
+	select {
+	case s.recvCh <- &packet:
+	case <-s.stopCh:
+	}
+
+	return true
 }
 
 func (s *Socket) encrypt(buf []byte, pClientID *ID, nReceiverVerifyKey, nSenderVerifyKey uint32) []byte {
@@ -260,16 +294,22 @@ func (s *Socket) decrypt(bufIn []byte, remoteIP uint32) ([]byte, uint32, uint32,
 }
 
 func (s *Socket) recvRoutine() {
-	for {
-		s.recv()
+	defer s.wg.Done()
+
+	for s.recv() {
 	}
 }
 
 func (s *Socket) sendRoutine() {
-	for {
-		packet := <-s.sendCh
+	defer s.wg.Done()
 
-		s.send(packet)
+	for {
+		select {
+		case packet := <-s.sendCh:
+			s.send(packet)
+		case <-s.stopCh:
+			return
+		}
 	}
 }
 
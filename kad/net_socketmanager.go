@@ -1,43 +1,78 @@
 package kad
 
+import (
+	"sync"
+)
+
 const (
-	socketNbr    = 10
-	updPortStart = 2000
+	socketNbr        = 10
+	socketSendChSize = 100
+	updPortStart     = 2000
 )
 
 // SocketManager is manager of sockets for distributing sending packets to sockets by round robin.
+// Sending packets are shaped before distributing.
 type SocketManager struct {
 	sockets []*Socket
 	round   int
 
-	sendCh chan *Packet
+	pShaper *Shaper
+
+	stopCh chan bool // closed for stopping all routines
+	wg     sync.WaitGroup
 }
 
-func (s *SocketManager) start(pPrefs *Prefs, recvCh, sendCh chan *Packet) bool {
-	s.sendCh = sendCh // channel for sending packets
+func (s *SocketManager) start(pPrefs *Prefs, pMetrics *Metrics, recvCh chan *Packet, pShaper *Shaper) error {
+	s.pShaper = pShaper // for sending packets
+	s.stopCh = make(chan bool)
+	s.sockets = nil
+	s.round = 0
 
 	// start sockets
 	for i := 0; i < socketNbr; i++ {
 		socket := &Socket{no: i}
-		sendCh1 := make(chan *Packet, cap(sendCh)/socketNbr)
+		sendCh1 := make(chan *Packet, socketSendChSize)
 		udpPort := uint16(updPortStart + i)
-		if !socket.start(pPrefs, recvCh, sendCh1, udpPort) {
-			return false
+		if err := socket.start(pPrefs, pMetrics, recvCh, sendCh1, udpPort, s.stopCh, &s.wg); err != nil {
+			s.stop() // stop started sockets
+			return err
 		}
 
 		s.sockets = append(s.sockets, socket)
 	}
 
 	// loop to distribute sending packets
+	s.wg.Add(1)
 	go s.sendRoutine()
 
-	return true
+	return nil
+}
+
+// stop is closing all sockets and waiting for all routines.
+func (s *SocketManager) stop() {
+	close(s.stopCh)
+
+	for _, socket := range s.sockets {
+		socket.close()
+	}
+
+	s.wg.Wait()
 }
 
 func (s *SocketManager) sendRoutine() {
+	defer s.wg.Done()
+
 	for {
-		packet := <-s.sendCh
-		s.sockets[s.round].sendCh <- packet
+		packet := s.pShaper.pop(s.stopCh)
+		if packet == nil || !s.pShaper.wait(packet, s.stopCh) {
+			return
+		}
+
+		select {
+		case s.sockets[s.round].sendCh <- packet:
+		case <-s.stopCh:
+			return
+		}
 
 		s.round++
 		s.round = s.round % socketNbr
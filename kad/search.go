@@ -12,20 +12,46 @@ const searchTolerance uint32 = 16777216
 // SearchResChSize is size of search result reponse channel for each web search.
 const SearchResChSize = 100
 
+// FileSearchResChSize is size of file search result channel, KAD never waits for sending.
+const FileSearchResChSize = 1
+
+const (
+	searchTypeKeyword byte = 0
+	searchTypeFile    byte = 1 // search sources and notes of file by hash
+)
+
 // Search x
 type Search struct {
-	no uint64 // search No.
+	no         uint64 // search No.
+	searchType byte
 
 	resCh chan *SearchRes
 
-	myKeywordStruct *com.MyKeywordStruct // from user and internet
+	myKeywordStruct *com.MyKeywordStruct // from user and internet, might be nil for raw search
+	pMulti          *MultiSearch         // nil if it's independent search
+
+	bContentFilter bool // files dropped by content filter are not sent, it's off for raw search without Filter
+
+	// for raw search
+	bRaw          bool
+	pFilter       *SearchFilter // nil if no filter
+	queryKeywords []string      // KAD keywords of query, files must contain all of them
+
+	// for file search
+	fileResCh chan *FileSearchRes
+	fileSize  uint64
 
 	targetID      ID
 	targetKeyword string
 
+	tStart      time.Time // for metrics
 	tExpires    int64
-	files       []*Ed2kFileStruct
-	fileHashMap map[[16]byte]bool
+	bFound      bool                         // any file found
+	files       []*Ed2kFileStruct            // including ones dropped by content filter, see filterRule
+	fileHashMap map[[16]byte]*Ed2kFileStruct // for file search, it's sources
+	noteHashMap map[[16]byte]*Ed2kFileStruct // for file search, peer might be source and publisher of notes
+
+	filteredHashMap map[[16]byte]string // files dropped by content filter, value is rule
 
 	contacts     []*Contact // contacts in searching target path
 	contactIPMap map[uint32]bool
@@ -42,11 +68,18 @@ func (s *Search) goSearch(contacts []*Contact, pPacketProcessor *PacketProcessor
 		// send KAD request according to tolerance
 		tolerance := s.calcSearchTolerance(pContact)
 		if tolerance > searchTolerance {
-			if pPacketProcessor.pPacketReqGuard.canPass(t, pContact.ip, kademlia2Req) {
-				pPacketProcessor.sendFindValue(pContact, &s.targetID)
+			if pPacketProcessor.pPacketReqGuard.canPass(t, pContact.ip, kademlia2Req, pContact.getVersion()) {
+				pPacketProcessor.sendFindValue(pContact, &s.targetID, packetPrioritySearch)
+			}
+		} else if s.searchType == searchTypeFile {
+			if pPacketProcessor.pPacketReqGuard.canPass(t, pContact.ip, kademlia2SearchSourceReq, pContact.getVersion()) {
+				pPacketProcessor.sendSearchSource(pContact, s.targetID.getHash(), s.fileSize)
+			}
+			if pPacketProcessor.pPacketReqGuard.canPass(t, pContact.ip, kademlia2SearchNotesReq, pContact.getVersion()) {
+				pPacketProcessor.sendSearchNotes(pContact, s.targetID.getHash(), s.fileSize)
 			}
 		} else {
-			if pPacketProcessor.pPacketReqGuard.canPass(t, pContact.ip, kademlia2SearchKeyReq) {
+			if pPacketProcessor.pPacketReqGuard.canPass(t, pContact.ip, kademlia2SearchKeyReq, pContact.getVersion()) {
 				pPacketProcessor.sendSearchKeyword(pContact, s.targetID.getHash())
 			}
 		}
@@ -56,37 +89,69 @@ func (s *Search) goSearch(contacts []*Contact, pPacketProcessor *PacketProcessor
 	}
 }
 
+// opcode of search request when contact is close enough to target
+func (s *Search) getSearchOpcode() byte {
+	if s.searchType == searchTypeFile {
+		return kademlia2SearchSourceReq
+	}
+
+	return kademlia2SearchKeyReq
+}
+
 func (s *Search) calcSearchTolerance(pContact *Contact) uint32 {
 	distance := s.targetID.getXor(pContact.getKadID())
 	return distance.get32BitChunk(0)
 }
 
-func (s *Search) addFiles(files []*Ed2kFileStruct) []*Ed2kFileStruct {
-	var newFiles []*Ed2kFileStruct
+// addFiles is returning new files, and known files of which fake score is changed by names from other publishers.
+// Files dropped by content filter are kept for searches without content filter, see checkContent.
+func (s *Search) addFiles(files []*Ed2kFileStruct, pMetrics *Metrics) ([]*Ed2kFileStruct, []*Ed2kFileStruct) {
+	var newFiles, rescoredFiles []*Ed2kFileStruct
 	for _, file := range files {
-		if s.fileHashMap[file.Hash] {
+		if pFile := s.fileHashMap[file.Hash]; pFile != nil {
+			// same file published by others, the same hash under many unrelated names is likely fake
+			if pFile.addOtherName(file.Name) && pFile.rescore() && !containsFile(newFiles, pFile) && !containsFile(rescoredFiles, pFile) {
+				rescoredFiles = append(rescoredFiles, pFile)
+			}
 			continue
 		}
 
-		//log.Println(file.Name)
+		// check by content filter
+		filterFile := com.FilterFile{Name: file.Name, Size: file.Size, Type: file.Type, Hash: file.Hash[:]}
+		if reason := com.CheckContent(&filterFile); reason != nil {
+			if s.filteredHashMap == nil {
+				s.filteredHashMap = make(map[[16]byte]string)
+			}
+			s.filteredHashMap[file.Hash] = reason.Rule
+			file.filterRule = reason.Rule
 
-		// check if yellow media or not
-		if com.IsYellow(file.Name) {
-			continue
+			pMetrics.inc(metricFilesFiltered, reason.Rule)
+			com.HhjLog.Debugf("Search %s filtered %s, %s", s.targetKeyword, file.Name, reason)
 		}
 
-		s.fileHashMap[file.Hash] = true
+		file.rescore()
+		s.fileHashMap[file.Hash] = file
 
 		s.files = append(s.files, file)
 		newFiles = append(newFiles, file)
 	}
 
-	return newFiles
+	return newFiles, rescoredFiles
+}
+
+func containsFile(files []*Ed2kFileStruct, pFile *Ed2kFileStruct) bool {
+	for _, file := range files {
+		if file == pFile {
+			return true
+		}
+	}
+
+	return false
 }
 
 // Conver to file link according to user search keywords
 func (s *Search) convert2FileLink(file *Ed2kFileStruct) *com.Ed2kFileLink {
-	if file.Type != "Video" {
+	if file.Type != com.Ed2kFileTypeVideo {
 		return nil
 	}
 
@@ -98,11 +163,13 @@ func (s *Search) convert2FileLink(file *Ed2kFileStruct) *com.Ed2kFileLink {
 
 	// check if season matched with user input
 	// we don't care about episode
-	if s.myKeywordStruct.MyKeyword.Season != -1 && s.myKeywordStruct.MyKeyword.Season != fileInfo.Season {
+	myKeyword := s.myKeywordStruct.MyKeyword
+	if myKeyword != nil && myKeyword.Season != -1 && myKeyword.Season != fileInfo.Season {
 		return nil
 	}
 
-	fileLink := com.Ed2kFileLink{FileInfo: *fileInfo, Name: file.Name, Size: file.Size, Avail: file.Avail, Hash: file.Hash[:]}
+	fileLink := com.Ed2kFileLink{FileInfo: *fileInfo, Name: file.Name, Size: file.Size, Avail: file.Avail, Hash: file.Hash[:], AICHHash: file.AICHHash,
+		FakeScore: file.fakeScore, FakeReasons: file.fakeReasons}
 
 	return &fileLink
 }
@@ -119,3 +186,197 @@ func (s *Search) convert2FileLinks(files []*Ed2kFileStruct) []*com.Ed2kFileLink
 
 	return fileLinks
 }
+
+// Drop files by content filter if it's on
+func (s *Search) checkContent(files []*Ed2kFileStruct) []*Ed2kFileStruct {
+	if !s.bContentFilter {
+		return files
+	}
+
+	var newFiles []*Ed2kFileStruct
+	for _, file := range files {
+		if file.filterRule == "" {
+			newFiles = append(newFiles, file)
+		}
+	}
+
+	return newFiles
+}
+
+// Filter files of raw search
+func (s *Search) filterFiles(files []*Ed2kFileStruct) []*Ed2kFileStruct {
+	var newFiles []*Ed2kFileStruct
+	for _, file := range files {
+		if s.pFilter != nil && !s.pFilter.pass(file) {
+			continue
+		}
+
+		if s.queryKeywords != nil && !containsKadKeywords(file.Name, s.queryKeywords) {
+			continue
+		}
+
+		newFiles = append(newFiles, file)
+	}
+
+	return newFiles
+}
+
+// Like eMule, file name should contain all keywords
+func containsKadKeywords(name string, keywords []string) bool {
+	nameKeywordMap := make(map[string]bool)
+	for _, keyword := range getKadKeywords(name) {
+		nameKeywordMap[keyword] = true
+	}
+
+	for _, keyword := range keywords {
+		if !nameKeywordMap[keyword] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Send files to user.
+// They're converted to file links matched with items, for raw search, it's optional.
+func (s *Search) sendFiles(files []*Ed2kFileStruct) {
+	files = s.checkContent(files)
+	if files == nil {
+		return
+	}
+
+	if !s.bRaw {
+		s.sendFileLinks(s.convert2FileLinks(files))
+		return
+	}
+
+	files = s.filterFiles(files)
+	if files == nil {
+		return
+	}
+
+	searchRes := SearchRes{Files: files}
+	if s.myKeywordStruct != nil && s.myKeywordStruct.Items != nil {
+		searchRes.FileLinks = s.convert2FileLinks(files)
+	}
+
+	if len(s.resCh) < cap(s.resCh) {
+		s.resCh <- &searchRes
+	}
+}
+
+// Send files again whose fake score is changed, user replaces them by hash.
+// For raw search, only file links are sent again since raw files have no score.
+func (s *Search) resendFiles(files []*Ed2kFileStruct) {
+	files = s.checkContent(files)
+	if files == nil {
+		return
+	}
+
+	if !s.bRaw {
+		s.sendFileLinks(s.convert2FileLinks(files))
+		return
+	}
+
+	if s.myKeywordStruct == nil || s.myKeywordStruct.Items == nil {
+		return
+	}
+
+	fileLinks := s.convert2FileLinks(s.filterFiles(files))
+	if fileLinks != nil && len(s.resCh) < cap(s.resCh) {
+		s.resCh <- &SearchRes{FileLinks: fileLinks}
+	}
+}
+
+// Send file links to user, or to multi-keyword search for combining.
+func (s *Search) sendFileLinks(fileLinks []*com.Ed2kFileLink) {
+	if fileLinks == nil {
+		return
+	}
+
+	if s.pMulti != nil {
+		s.pMulti.addFileLinks(s.no, fileLinks)
+		return
+	}
+
+	if len(s.resCh) < cap(s.resCh) {
+		searchRes := SearchRes{FileLinks: fileLinks}
+		s.resCh <- &searchRes
+	}
+}
+
+// Add sources and notes of file search, answer hash is source or publisher ID.
+func (s *Search) addFileAnswers(files []*Ed2kFileStruct) {
+	for _, file := range files {
+		hashMap := s.fileHashMap
+		if file.sourceType == 0 {
+			if s.noteHashMap == nil {
+				s.noteHashMap = make(map[[16]byte]*Ed2kFileStruct)
+			}
+			hashMap = s.noteHashMap
+		}
+
+		if hashMap[file.Hash] != nil {
+			continue
+		}
+
+		hashMap[file.Hash] = file
+		s.files = append(s.files, file)
+	}
+}
+
+// Combine sources and notes to file details.
+func (s *Search) getFileSearchRes() *FileSearchRes {
+	res := FileSearchRes{Ed2kFileStruct: Ed2kFileStruct{Hash: s.targetID.get(), Size: s.fileSize}}
+
+	nameCounts := make(map[string]int)
+	var names []string
+	var ratingSum int
+	for _, file := range s.files {
+		if file.sourceType != 0 {
+			res.Avail++
+			continue
+		}
+
+		// note
+		res.NoteNbr++
+
+		if file.Name != "" {
+			if nameCounts[file.Name] == 0 {
+				names = append(names, file.Name)
+			}
+			nameCounts[file.Name]++
+		}
+
+		if res.Size == 0 {
+			res.Size = file.Size
+		}
+
+		if file.Rating > 0 {
+			ratingSum += int(file.Rating)
+			res.RatingNbr++
+		}
+
+		if file.Comment != "" {
+			res.Comments = append(res.Comments, file.Comment)
+		}
+	}
+
+	if res.RatingNbr > 0 {
+		res.Rating = uint8(ratingSum / res.RatingNbr)
+	}
+
+	// the most popular name
+	for _, name := range names {
+		if nameCounts[name] > nameCounts[res.Name] {
+			res.Name = name
+		}
+	}
+	for _, name := range names {
+		if name != res.Name {
+			res.OtherNames = append(res.OtherNames, name)
+		}
+	}
+
+	return &res
+}
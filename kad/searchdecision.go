@@ -1,16 +1,58 @@
 package kad
 
+import (
+	"sort"
+	"time"
+)
+
 const bootstrapSearchContactNbr = 10
 
+// more candidates than we need so that unhealthy ones can be skipped
+const searchCandidateFactor = 3
+
+// contacts below this health are likely dropping our requests
+const searchMinHealth = 20
+
 // SearchDecision x
 type SearchDecision struct {
-	pOnliner *ContactOnliner
+	pOnliner        *ContactOnliner
+	pPacketReqGuard *PacketReqGuard
 }
 
-func (sd *SearchDecision) start(pOnliner *ContactOnliner) {
+func (sd *SearchDecision) start(pOnliner *ContactOnliner, pPacketReqGuard *PacketReqGuard) {
 	sd.pOnliner = pOnliner
+	sd.pPacketReqGuard = pPacketReqGuard
 }
 
 func (sd *SearchDecision) newSearch(pSearch *Search) []*Contact {
-	return sd.pOnliner.getSearchContacts(pSearch)
+	t := time.Now().Unix()
+
+	candidates := sd.pOnliner.getSearchContacts(pSearch, bootstrapSearchContactNbr*searchCandidateFactor)
+	return getHealthyContacts(t, candidates, sd.pPacketReqGuard)
+}
+
+// getHealthyContacts is the healthiest contacts of @candidates, unhealthy ones are skipped.
+// Candidates are in order of online time, keep it for same health.
+func getHealthyContacts(t int64, candidates []*Contact, pPacketReqGuard *PacketReqGuard) []*Contact {
+	var contacts []*Contact
+	healthMap := make(map[uint32]int)
+	for _, pContact := range candidates {
+		health := pPacketReqGuard.getHealth(t, pContact.ip)
+		if health < searchMinHealth {
+			continue
+		}
+
+		healthMap[pContact.ip] = health
+		contacts = append(contacts, pContact)
+	}
+
+	sort.SliceStable(contacts, func(i, j int) bool {
+		return healthMap[contacts[i].ip] > healthMap[contacts[j].ip]
+	})
+
+	if len(contacts) > bootstrapSearchContactNbr {
+		contacts = contacts[:bootstrapSearchContactNbr]
+	}
+
+	return contacts
 }
@@ -1,6 +1,7 @@
 package kad
 
 import (
+	"fmt"
 	"hahajing/com"
 	"log"
 	"time"
@@ -9,6 +10,7 @@ import (
 // SearchManager x
 type SearchManager struct {
 	pPacketProcessor *PacketProcessor
+	pMetrics         *Metrics
 
 	searchCount uint64
 	searchMap   map[[16]byte][]*Search // key is 128bits KAD hash of keyword
@@ -16,12 +18,13 @@ type SearchManager struct {
 	decision SearchDecision
 }
 
-func (sm *SearchManager) start(pPacketProcessor *PacketProcessor, pOnliner *ContactOnliner) {
+func (sm *SearchManager) start(pMetrics *Metrics, pPacketProcessor *PacketProcessor, pOnliner *ContactOnliner) {
 	sm.pPacketProcessor = pPacketProcessor
+	sm.pMetrics = pMetrics
 
 	sm.searchMap = make(map[[16]byte][]*Search)
 
-	sm.decision.start(pOnliner)
+	sm.decision.start(pOnliner, pPacketProcessor.pPacketReqGuard)
 }
 
 func (sm *SearchManager) goSearch(pSearch *Search) {
@@ -32,53 +35,141 @@ func (sm *SearchManager) goSearch(pSearch *Search) {
 }
 
 func (sm *SearchManager) newSearch(pSearchReq *SearchReq) {
-	for _, targetKeyword := range pSearchReq.MyKeywordStruct.TargetKeywords {
-		no := sm.searchCount
-		sm.searchCount++
-
-		targetHash := sm.getKeywordHash(targetKeyword)
-
-		search := Search{
-			no:              no,
-			resCh:           pSearchReq.ResCh,
-			myKeywordStruct: pSearchReq.MyKeywordStruct,
-			targetID:        ID{hash: targetHash},
-			targetKeyword:   targetKeyword,
-			tExpires:        time.Now().Unix() + searchExpires,
-			fileHashMap:     make(map[[16]byte]bool),
-			contactIPMap:    make(map[uint32]bool)}
-
-		searches := append(sm.searchMap[targetHash], &search)
-		sm.searchMap[targetHash] = searches
-
-		if len(searches) == 1 { // we're the first one
-			sm.goSearch(&search)
-		} else {
-			log.Printf("Ongoing search: %s", targetKeyword)
-
-			// There's same target search ongoing, get the first one
-			pSearch := searches[0]
-
-			// send matched files to user for each search
-			fileLinks := pSearch.convert2FileLinks(pSearch.files)
-			if fileLinks != nil {
-				if len(pSearch.resCh) < cap(pSearch.resCh) {
-					searchRes := SearchRes{FileLinks: fileLinks}
-					pSearch.resCh <- &searchRes
-				}
-			}
+	// raw search by plain keywords
+	if pSearchReq.Query != "" {
+		for _, targetKeyword := range sm.getTargetKeywords([]string{pSearchReq.Query}) {
+			sm.newKeywordSearch(pSearchReq, targetKeyword, nil)
+		}
+		return
+	}
+
+	myKeywordStruct := pSearchReq.MyKeywordStruct
+	if myKeywordStruct == nil {
+		com.HhjLog.Warning("Search without keywords")
+		return
+	}
+
+	if pSearchReq.Combine == SearchCombineNone || pSearchReq.getFilter() != nil || len(myKeywordStruct.KeywordGroups) == 0 {
+		for _, targetKeyword := range sm.getTargetKeywords(myKeywordStruct.TargetKeywords) {
+			sm.newKeywordSearch(pSearchReq, targetKeyword, nil)
+		}
+		return
+	}
+
+	// search all keywords of each group and combine results
+	for _, group := range myKeywordStruct.KeywordGroups {
+		group = sm.getTargetKeywords(group)
+		if len(group) == 0 {
+			continue
+		}
+
+		pMulti := newMultiSearch(pSearchReq.Combine, pSearchReq.ResCh, len(group))
+		for _, keyword := range group {
+			sm.newKeywordSearch(pSearchReq, keyword, pMulti)
 		}
 	}
 }
 
-func (sm *SearchManager) getKeywordHash(keyword string) [16]byte {
-	md4 := Md4Sum{}
-	md4.calculate([]byte(keyword))
+// Normalize keywords to what eMule publishes, like eMule searching, the first KAD keyword is target.
+func (sm *SearchManager) getTargetKeywords(keywords []string) []string {
+	var targetKeywords []string
+	targetKeywordMap := make(map[string]bool)
+	for _, keyword := range keywords {
+		kadKeywords := getKadKeywords(keyword)
+		if len(kadKeywords) == 0 {
+			com.HhjLog.Warningf("Invalid KAD keyword: %s", keyword)
+			continue
+		}
+
+		targetKeyword := kadKeywords[0]
+		if !targetKeywordMap[targetKeyword] {
+			targetKeywordMap[targetKeyword] = true
+			targetKeywords = append(targetKeywords, targetKeyword)
+		}
+	}
+
+	return targetKeywords
+}
+
+func (sm *SearchManager) newKeywordSearch(pSearchReq *SearchReq, targetKeyword string, pMulti *MultiSearch) {
+	no := sm.searchCount
+	sm.searchCount++
+
+	targetHash := sm.getKeywordHash(targetKeyword)
+
+	search := Search{
+		no:              no,
+		resCh:           pSearchReq.ResCh,
+		myKeywordStruct: pSearchReq.MyKeywordStruct,
+		pMulti:          pMulti,
+		targetID:        ID{hash: targetHash},
+		targetKeyword:   targetKeyword,
+		tStart:          time.Now(),
+		tExpires:        time.Now().Unix() + searchExpires,
+		fileHashMap:     make(map[[16]byte]*Ed2kFileStruct),
+		contactIPMap:    make(map[uint32]bool)}
+
+	if pFilter := pSearchReq.getFilter(); pSearchReq.Query != "" || pFilter != nil {
+		search.bRaw = true
+		search.pFilter = pFilter
+		search.queryKeywords = getKadKeywords(pSearchReq.Query)
+	}
+
+	// raw search is what user asks for, content filter is only on if Filter is given
+	search.bContentFilter = !search.bRaw || pSearchReq.Filter != nil
+
+	searches := append(sm.searchMap[targetHash], &search)
+	sm.searchMap[targetHash] = searches
+	sm.pMetrics.inc(metricSearches, "keyword")
+
+	if len(searches) == 1 { // we're the first one
+		sm.goSearch(&search)
+	} else {
+		log.Printf("Ongoing search: %s", targetKeyword)
+
+		// There's same target search ongoing, get the first one
+		pSearch := searches[0]
+
+		// send matched files to user for each search
+		search.sendFiles(pSearch.files)
+	}
+}
+
+func (sm *SearchManager) newFileSearch(pFileSearchReq *FileSearchReq) {
+	if cap(pFileSearchReq.ResCh) == 0 {
+		com.HhjLog.Warningf("File search of %X is ignored, result channel is not buffered", pFileSearchReq.Hash)
+		return
+	}
+
+	no := sm.searchCount
+	sm.searchCount++
+
+	targetHash := pFileSearchReq.Hash
+
+	search := Search{
+		no:            no,
+		searchType:    searchTypeFile,
+		fileResCh:     pFileSearchReq.ResCh,
+		fileSize:      pFileSearchReq.Size,
+		targetID:      ID{hash: targetHash},
+		targetKeyword: fmt.Sprintf("%X", com.ConvertEd2kHash32(targetHash[:])), // file hash for log
+		tStart:        time.Now(),
+		tExpires:      time.Now().Unix() + searchExpires,
+		fileHashMap:   make(map[[16]byte]*Ed2kFileStruct),
+		contactIPMap:  make(map[uint32]bool)}
 
-	// change to big endian for each uint32
-	hash := com.ConvertEd2kHash32(md4.getRawHash())
+	searches := append(sm.searchMap[targetHash], &search)
+	sm.searchMap[targetHash] = searches
+	sm.pMetrics.inc(metricSearches, "file")
 
-	return hash
+	// there's same file search ongoing, result will be sent when search expires
+	if len(searches) == 1 {
+		sm.goSearch(&search)
+	}
+}
+
+func (sm *SearchManager) getKeywordHash(keyword string) [16]byte {
+	return getKadKeywordHash(keyword)
 }
 
 func (sm *SearchManager) addKademlia2SearchRes(pMsg *Kademlia2SearchResMsg) {
@@ -90,21 +181,26 @@ func (sm *SearchManager) addKademlia2SearchRes(pMsg *Kademlia2SearchResMsg) {
 
 	// add files into the first one
 	pSearch := searches[0]
-	newFiles := pSearch.addFiles(pMsg.files)
-	if newFiles == nil {
+	if !pSearch.bFound && len(pMsg.files) > 0 {
+		pSearch.bFound = true
+		sm.pMetrics.observe(metricSearchFirstResult, "", time.Since(pSearch.tStart).Seconds())
+	}
+
+	if pSearch.searchType == searchTypeFile {
+		pSearch.addFileAnswers(pMsg.files)
 		return
 	}
 
+	newFiles, rescoredFiles := pSearch.addFiles(pMsg.files, sm.pMetrics)
+
 	// send new file links to user for each search
 	for _, pSearch := range searches {
-		// convert to file links matched with user search keywords
-		fileLinks := pSearch.convert2FileLinks(newFiles)
-
-		if fileLinks != nil {
-			if len(pSearch.resCh) < cap(pSearch.resCh) {
-				searchRes := SearchRes{FileLinks: fileLinks}
-				pSearch.resCh <- &searchRes
-			}
+		// convert to file links matched with user search keywords, or filtered by file types
+		if newFiles != nil {
+			pSearch.sendFiles(newFiles)
+		}
+		if rescoredFiles != nil {
+			pSearch.resendFiles(rescoredFiles)
 		}
 	}
 }
@@ -124,13 +220,77 @@ func (sm *SearchManager) addKademlia2Res(pMsg *Kademlia2ResMsg) bool {
 	return true
 }
 
+func (sm *SearchManager) isIdle() bool {
+	return len(sm.searchMap) == 0
+}
+
+// stop is finishing all ongoing searches, file searches and multi-keyword searches still get their results.
+func (sm *SearchManager) stop() {
+	for key, searches := range sm.searchMap {
+		delete(sm.searchMap, key)
+		sm.finishSearches(searches)
+	}
+
+	sm.pMetrics.set(metricSearchesActive, "", 0)
+}
+
 func (sm *SearchManager) tickProcess() {
 	t := time.Now().Unix()
 
+	activeNbr := 0
 	for key, searches := range sm.searchMap {
 		pSearch := searches[len(searches)-1]
 		if t >= pSearch.tExpires {
 			delete(sm.searchMap, key)
+			sm.finishSearches(searches)
+		} else {
+			activeNbr += len(searches)
+		}
+	}
+
+	sm.pMetrics.set(metricSearchesActive, "", float64(activeNbr))
+}
+
+// finish searches which are removed from search map
+func (sm *SearchManager) finishSearches(searches []*Search) {
+	sm.observeFinishedSearches(searches)
+
+	if searches[0].searchType == searchTypeFile {
+		sm.sendFileSearchRes(searches)
+		return
+	}
+
+	for _, pSearch := range searches {
+		if pSearch.pMulti != nil {
+			pSearch.pMulti.finishSearch()
+		}
+	}
+}
+
+func (sm *SearchManager) observeFinishedSearches(searches []*Search) {
+	// files are only added into the first one
+	fileNbr := searches[0].getPassedFileNbr()
+
+	for _, pSearch := range searches {
+		sm.pMetrics.observe(metricSearchDuration, "", time.Since(pSearch.tStart).Seconds())
+		sm.pMetrics.observe(metricSearchResults, "", float64(fileNbr))
+		if fileNbr == 0 {
+			sm.pMetrics.inc(metricSearchesWithoutFiles, "")
+		}
+	}
+}
+
+// send combined file details to user for each file search
+func (sm *SearchManager) sendFileSearchRes(searches []*Search) {
+	for _, pSearch := range searches {
+		// sources and notes are only added into the first one
+		res := searches[0].getFileSearchRes()
+		if pSearch.fileSize != 0 {
+			res.Size = pSearch.fileSize
+		}
+
+		if len(pSearch.fileResCh) < cap(pSearch.fileResCh) {
+			pSearch.fileResCh <- res
 		}
 	}
 }
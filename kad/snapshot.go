@@ -0,0 +1,140 @@
+package kad
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// SnapshotReq is request of read-only snapshot of KAD state.
+type SnapshotReq struct {
+	ResCh chan *Snapshot
+}
+
+// Snapshot is read-only snapshot of routing table and ongoing searches.
+type Snapshot struct {
+	Time     time.Time
+	Contacts []*ContactSnapshot
+	Searches []*SearchSnapshot
+}
+
+// ContactSnapshot x
+type ContactSnapshot struct {
+	ID          string // empty if unknown
+	IP          string
+	UDPPort     uint16
+	Version     string
+	Verified    bool
+	Created     time.Time
+	LiveExpires time.Time
+	LiveLeft    int64 // second to next live check
+	RTT         int64 // second
+	Health      int   // 0 - 100, from packet request guard
+}
+
+// SearchSnapshot x
+type SearchSnapshot struct {
+	No              uint64
+	Type            string
+	Target          string // keyword or file hash
+	TargetID        string
+	ContactsQueried int
+	FilesFound      int
+	FilesFiltered   map[string]int // dropped by content filter, key is rule
+	TimeLeft        int64          // second
+}
+
+func newContactSnapshot(t int64, pContact *Contact, health int) *ContactSnapshot {
+	snapshot := ContactSnapshot{
+		IP:          iIP2Str(pContact.ip),
+		UDPPort:     pContact.updPort,
+		Version:     getVersionStr(pContact.version),
+		Verified:    pContact.bVerified,
+		Created:     time.Unix(pContact.tCreated, 0),
+		LiveExpires: time.Unix(pContact.tLiveExpires, 0),
+		LiveLeft:    pContact.tLiveExpires - t,
+		RTT:         pContact.tRTT,
+		Health:      health}
+
+	if pContact.pKadID != nil {
+		snapshot.ID = fmt.Sprintf("%X", pContact.pKadID.getHash())
+	}
+
+	return &snapshot
+}
+
+func (cm *ContactManager) getSnapshot(t int64) []*ContactSnapshot {
+	var contacts []*ContactSnapshot
+	for _, pContact := range cm.contactMap {
+		contacts = append(contacts, newContactSnapshot(t, pContact, cm.onliner.pPacketReqGuard.getHealth(t, pContact.ip)))
+	}
+
+	// longer online first
+	sort.Slice(contacts, func(i, j int) bool {
+		return contacts[i].Created.Before(contacts[j].Created)
+	})
+
+	return contacts
+}
+
+func (sm *SearchManager) getSnapshot(t int64) []*SearchSnapshot {
+	var searches []*SearchSnapshot
+	for _, group := range sm.searchMap {
+		// contacts and files are only in the first one
+		pFirst := group[0]
+
+		for _, pSearch := range group {
+			searchType := "keyword"
+			if pSearch.searchType == searchTypeFile {
+				searchType = "file"
+			}
+
+			searches = append(searches, &SearchSnapshot{
+				No:              pSearch.no,
+				Type:            searchType,
+				Target:          pSearch.targetKeyword,
+				TargetID:        fmt.Sprintf("%X", pSearch.targetID.getHash()),
+				ContactsQueried: len(pFirst.contacts),
+				FilesFound:      pFirst.getPassedFileNbr(),
+				FilesFiltered:   pFirst.getFilteredNbrs(),
+				TimeLeft:        pSearch.tExpires - t})
+		}
+	}
+
+	sort.Slice(searches, func(i, j int) bool {
+		return searches[i].No < searches[j].No
+	})
+
+	return searches
+}
+
+// getPassedFileNbr is number of found files not dropped by content filter.
+func (s *Search) getPassedFileNbr() int {
+	return len(s.files) - len(s.filteredHashMap)
+}
+
+// getFilteredNbrs is number of files dropped by each rule of content filter, nil if none.
+func (s *Search) getFilteredNbrs() map[string]int {
+	if len(s.filteredHashMap) == 0 {
+		return nil
+	}
+
+	nbrs := make(map[string]int)
+	for _, rule := range s.filteredHashMap {
+		nbrs[rule]++
+	}
+
+	return nbrs
+}
+
+func (k *Kad) processSnapshotReq(pReq *SnapshotReq) {
+	now := time.Now()
+	snapshot := Snapshot{
+		Time:     now,
+		Contacts: k.contactManager.getSnapshot(now.Unix()),
+		Searches: k.searchManager.getSnapshot(now.Unix())}
+
+	if len(pReq.ResCh) < cap(pReq.ResCh) {
+		pReq.ResCh <- &snapshot
+	}
+}
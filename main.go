@@ -1,20 +1,71 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"hahajing/com"
 	"hahajing/door"
 	"hahajing/kad"
 	"hahajing/web"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 )
 
+const shutdownTimeout = 5 // second
+
 var kadInstance kad.Kad
 var webInstance web.Web
 var doorInstance door.Door
 var keywordManager = com.NewKeywordManager()
 
+var filterProfile = flag.String("filter", "default", "content filter profile in config/filter")
+var doorParallel = flag.Bool("door-parallel", false, "query all title providers at the same time and merge their items")
+var titleThreshold = flag.Float64("title-threshold", com.TitleMatchThreshold, "minimal score from 0 to 1 for file name to match title")
+
 func main() {
-	kadInstance.Start()
+	flag.Parse()
+	com.TitleMatchThreshold = *titleThreshold
+
+	if err := com.StartContentFilter(*filterProfile); err != nil {
+		com.HhjLog.Criticalf("Load content filter profile %s failed, built-in rules are used: %s", *filterProfile, err)
+	}
+
+	if err := kadInstance.Start(); err != nil {
+		com.HhjLog.Panicf("KAD start failed: %s", err)
+	}
+	// warm start, so that searches needn't wait for Door after restarting
+	keywordFileName := com.GetKeywordManagerFileName()
+	if err := keywordManager.Load(keywordFileName); err != nil && !os.IsNotExist(err) {
+		com.HhjLog.Errorf("Load keyword manager failed: %s", err)
+	}
+	keywordManager.StartSaving(keywordFileName)
+	go waitShutdown(keywordFileName)
+
+	doorInstance.Parallel = *doorParallel
 	doorInstance.Start(keywordManager)
 
-	webInstance.Start(kadInstance.SearchReqCh, doorInstance.KeywordCheckReqCh, keywordManager)
+	webInstance.Start(kadInstance.SearchReqCh, doorInstance.KeywordCheckReqCh, keywordManager, kadInstance.SnapshotReqCh, kadInstance.Metrics)
+}
+
+// waitShutdown is saving keyword manager and stopping KAD when interrupted.
+func waitShutdown(keywordFileName string) {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	<-sigCh
+
+	com.HhjLog.Notice("Shutting down...")
+
+	if err := keywordManager.Save(keywordFileName); err != nil {
+		com.HhjLog.Errorf("Save keyword manager failed: %s", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout*time.Second)
+	if err := kadInstance.Stop(ctx); err != nil {
+		com.HhjLog.Errorf("KAD stop failed: %s", err)
+	}
+	cancel()
+
+	os.Exit(0)
 }
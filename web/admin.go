@@ -0,0 +1,105 @@
+package web
+
+import (
+	"encoding/json"
+	"hahajing/com"
+	"hahajing/kad"
+	"net"
+	"net/http"
+	"time"
+)
+
+const kadSnapshotWaitingTime = 3
+
+// AdminData x
+type AdminData struct {
+	Snapshot *kad.Snapshot
+}
+
+// Admin page is only for local operators, e.g. via SSH tunnel.
+func isLocalRequest(r *http.Request) bool {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return false
+	}
+
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func (we *Web) getKadSnapshot() *kad.Snapshot {
+	if we.snapshotReqCh == nil {
+		return nil
+	}
+
+	// KAD might be stopped, both sending and waiting are limited
+	timeout := time.After(kadSnapshotWaitingTime * time.Second)
+	resCh := make(chan *kad.Snapshot, 1)
+	select {
+	case we.snapshotReqCh <- &kad.SnapshotReq{ResCh: resCh}:
+	case <-timeout:
+		return nil
+	}
+
+	select {
+	case snapshot := <-resCh:
+		return snapshot
+	case <-timeout:
+		return nil
+	}
+}
+
+func (we *Web) adminHandler(w http.ResponseWriter, r *http.Request) {
+	if !isLocalRequest(r) {
+		http.NotFound(w, r)
+		return
+	}
+
+	snapshot := we.getKadSnapshot()
+	if snapshot == nil {
+		http.Error(w, "KAD busy!", http.StatusServiceUnavailable)
+		return
+	}
+
+	err := we.adminTemplate.Execute(w, &AdminData{Snapshot: snapshot})
+	if err != nil {
+		com.HhjLog.Criticalf("Execute admin template failed: %s", err)
+	}
+}
+
+func (we *Web) adminJSONHandler(w http.ResponseWriter, r *http.Request) {
+	if !isLocalRequest(r) {
+		http.NotFound(w, r)
+		return
+	}
+
+	snapshot := we.getKadSnapshot()
+	if snapshot == nil {
+		http.Error(w, "KAD busy!", http.StatusServiceUnavailable)
+		return
+	}
+
+	data, _ := json.Marshal(snapshot)
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// reload content filter at once instead of waiting for file check
+func (we *Web) filterReloadHandler(w http.ResponseWriter, r *http.Request) {
+	if !isLocalRequest(r) {
+		http.NotFound(w, r)
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "POST only!", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := com.ReloadContentFilter(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write([]byte("OK"))
+}
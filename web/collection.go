@@ -0,0 +1,115 @@
+package web
+
+import (
+	"encoding/json"
+	"hahajing/com"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+const defaultCollectionName = "hahajing"
+
+// fileLinkForm is file link posted back from user browser, same as JSON of com.Ed2kFileLink.
+type fileLinkForm struct {
+	com.FileInfo
+
+	Name string
+	Size uint64
+	Link string
+}
+
+// get file links from posted form
+// @file: JSON of file link from search result
+// @link: plain ED2K link
+func getCollectionFileLinks(r *http.Request) []*com.Ed2kFileLink {
+	var fileLinks []*com.Ed2kFileLink
+	for _, data := range r.Form["file"] {
+		var form fileLinkForm
+		if err := json.Unmarshal([]byte(data), &form); err != nil {
+			continue
+		}
+
+		fileLink := com.ParseEd2kLink(form.Link)
+		if fileLink == nil {
+			continue
+		}
+		fileLink.FileInfo = form.FileInfo
+
+		fileLinks = append(fileLinks, fileLink)
+	}
+
+	for _, link := range r.Form["link"] {
+		fileLink := com.ParseEd2kLink(link)
+		if fileLink != nil {
+			fileLinks = append(fileLinks, fileLink)
+		}
+	}
+
+	return fileLinks
+}
+
+// filter file links by season, -1 means no filter.
+func filterCollectionFileLinks(fileLinks []*com.Ed2kFileLink, season int) []*com.Ed2kFileLink {
+	if season == -1 {
+		return fileLinks
+	}
+
+	var newFileLinks []*com.Ed2kFileLink
+	for _, fileLink := range fileLinks {
+		if fileLink.Season == season {
+			newFileLinks = append(newFileLinks, fileLink)
+		}
+	}
+
+	return newFileLinks
+}
+
+// collectionHandler returns eMule collection file of posted file links.
+// Form values:
+// @name: collection name
+// @format: "text" for text format, otherwise binary format
+// @season: only files of this season, optional
+// @file, @link: file links, see getCollectionFileLinks
+func (we *Web) collectionHandler(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "参数错误！", http.StatusBadRequest)
+		return
+	}
+
+	season := -1
+	if s := r.Form.Get("season"); s != "" {
+		v, err := strconv.Atoi(s)
+		if err != nil {
+			http.Error(w, "季数错误！", http.StatusBadRequest)
+			return
+		}
+		season = v
+	}
+
+	fileLinks := filterCollectionFileLinks(getCollectionFileLinks(r), season)
+	if len(fileLinks) == 0 {
+		http.Error(w, "没有文件！", http.StatusBadRequest)
+		return
+	}
+
+	name := com.StripString(r.Form.Get("name"))
+	if name == "" {
+		name = defaultCollectionName
+	}
+	collection := com.NewCollection(name, fileLinks)
+
+	fileName := url.PathEscape(name + com.CollectionExt)
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", "attachment; filename*=UTF-8''"+fileName)
+
+	var err error
+	if r.Form.Get("format") == "text" {
+		err = collection.WriteText(w)
+	} else {
+		err = collection.WriteBinary(w)
+	}
+	if err != nil {
+		com.HhjLog.Errorf("Write collection failed: %s", err)
+	}
+}
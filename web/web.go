@@ -2,10 +2,12 @@ package web
 
 import (
 	"encoding/json"
+	"flag"
 	"fmt"
 	"hahajing/com"
 	"hahajing/door"
 	"hahajing/kad"
+	htmltemplate "html/template"
 	"log"
 	"net"
 	"net/http"
@@ -20,6 +22,8 @@ import (
 const (
 	keywordCheckWaitingTime = 5
 	kadSearchWaitingTime    = 5
+	kadCombineWaitingTime   = 10 // second, combined results are sent once after KAD searches expire
+	userChooseWaitingTime   = 60 // second, for user choosing items
 )
 
 // webError is for user browser.
@@ -27,21 +31,37 @@ type webError struct {
 	Error string
 }
 
+// webCandidates is items for user browser to choose before KAD search.
+type webCandidates struct {
+	Candidates []*com.Item
+}
+
+// webChoice is indexes of candidates chosen by user browser, empty means all.
+type webChoice struct {
+	Choose []int
+}
+
 // Web x
 type Web struct {
 	searchReqCh       chan *kad.SearchReq
 	keywordCheckReqCh chan *door.KeywordCheckReq
+	snapshotReqCh     chan *kad.SnapshotReq
 
 	homeTemplate    *template.Template
+	adminTemplate   *htmltemplate.Template
 	keywordManager  *com.KeywordManager
 	userSearchTrack *UserSearchTrack
+	kadMetrics      *kad.Metrics
 }
 
 // Start x
-func (we *Web) Start(searchReqCh chan *kad.SearchReq, keywordCheckReqCh chan *door.KeywordCheckReq, keywordManager *com.KeywordManager) {
+func (we *Web) Start(searchReqCh chan *kad.SearchReq, keywordCheckReqCh chan *door.KeywordCheckReq, keywordManager *com.KeywordManager,
+	snapshotReqCh chan *kad.SnapshotReq, kadMetrics *kad.Metrics) {
 	we.searchReqCh = searchReqCh
 	we.keywordCheckReqCh = keywordCheckReqCh
+	we.snapshotReqCh = snapshotReqCh
 	we.keywordManager = keywordManager
+	we.kadMetrics = kadMetrics
 
 	we.userSearchTrack = NewUserSearchTrack()
 
@@ -53,6 +73,21 @@ func (we *Web) Start(searchReqCh chan *kad.SearchReq, keywordCheckReqCh chan *do
 	}
 	we.homeTemplate = tmpl
 
+	// admin page is rendered with data from KAD network, so HTML escaping is needed
+	adminTmpl, err := htmltemplate.ParseFiles(path + "/config/web/admin.html")
+	if err != nil {
+		log.Panic("Admin page failed!")
+	}
+	we.adminTemplate = adminTmpl
+
+	// optional link formats besides ED2K link, e.g. magnet
+	linkFormatsFileName := path + "/config/web/links.json"
+	if _, err := os.Stat(linkFormatsFileName); err == nil {
+		if err := com.LoadLinkFormats(linkFormatsFileName); err != nil {
+			com.HhjLog.Criticalf("Load link formats failed: %s", err)
+		}
+	}
+
 	// at last start sever
 	we.startServer()
 }
@@ -104,12 +139,19 @@ func (we *Web) readSearchInput(ws *websocket.Conn) (*com.MyKeyword, string) {
 func (we *Web) checkKeywordsFromKeywordManager(myKeyword *com.MyKeyword) ([]*com.Item, bool) {
 	// get from keyword manager
 	items := we.keywordManager.Get(myKeyword.SearchKeywords)
-	if items == nil { // not existing in keyword manager
+	if items != nil {
+		// filter
+		return com.FilterItems(items, myKeyword), true
+	}
+
+	// reuse items of broader keywords, like walking dead for the walking dead s05,
+	// but not narrower ones which miss other titles, like the walking dead for dead
+	items = com.FilterItems(we.keywordManager.GetRelated(myKeyword.SearchKeywords, false), myKeyword)
+	if len(items) == 0 { // not existing in keyword manager
 		return nil, false
 	}
 
-	// filter
-	return com.FilterItems(items, myKeyword), true
+	return items, true
 }
 
 func (we *Web) writeError(ws *websocket.Conn, errStr string) {
@@ -117,14 +159,38 @@ func (we *Web) writeError(ws *websocket.Conn, errStr string) {
 	ws.Write(data)
 }
 
+// getSearchCombine is how to combine KAD keyword searches, by combine=union or combine=intersect of URL query.
+func getSearchCombine(ws *websocket.Conn) byte {
+	switch ws.Request().URL.Query().Get("combine") {
+	case "union":
+		return kad.SearchCombineUnion
+	case "intersect":
+		return kad.SearchCombineIntersect
+	}
+
+	return kad.SearchCombineNone
+}
+
 func (we *Web) send2Kad(ws *websocket.Conn, myKeywordStruct *com.MyKeywordStruct) {
 	resCh := make(chan *kad.SearchRes, kad.SearchResChSize)
-	searchReq := kad.SearchReq{ResCh: resCh, MyKeywordStruct: myKeywordStruct}
-	we.searchReqCh <- &searchReq
+	searchReq := kad.SearchReq{ResCh: resCh, MyKeywordStruct: myKeywordStruct, Combine: getSearchCombine(ws)}
+
+	// KAD might be stopped or too busy, don't wait for it
+	select {
+	case we.searchReqCh <- &searchReq:
+	default:
+		we.writeError(ws, "服务器繁忙，请稍后重试！")
+		return
+	}
+
+	waitingTime := time.Duration(kadSearchWaitingTime)
+	if searchReq.Combine != kad.SearchCombineNone {
+		waitingTime = kadCombineWaitingTime
+	}
 
 	// waiting result from KAD
 	found := false
-	fileLinks := make(map[[16]byte]bool)
+	fileLinks := make(map[[16]byte]int) // fake score sent, sent again if rescored
 	for {
 		select {
 		case pSearchRes := <-resCh:
@@ -134,11 +200,12 @@ func (we *Web) send2Kad(ws *websocket.Conn, myKeywordStruct *com.MyKeywordStruct
 				}
 				found = true
 				hash := fileLink.GetHash()
-				if !fileLinks[hash] {
+				if fakeScore, ok := fileLinks[hash]; !ok || fakeScore != fileLink.FakeScore {
+					fileLinks[hash] = fileLink.FakeScore
 					ws.Write(fileLink.ToJSON())
 				}
 			}
-		case <-time.After(kadSearchWaitingTime * time.Second):
+		case <-time.After(waitingTime * time.Second):
 			if !found {
 				we.writeError(ws, "搜索超时，请重试！")
 			}
@@ -169,6 +236,15 @@ func (we *Web) searchHandler(ws *websocket.Conn) {
 		return
 	}
 
+	// let user choose exact items, e.g. one of TV and movies of same name
+	if len(items) > 1 {
+		items, errStr = we.chooseItems(ws, items)
+		if len(items) == 0 {
+			we.writeError(ws, errStr)
+			return
+		}
+	}
+
 	// add valid new search keywords for statistic
 	we.userSearchTrack.addSearchKeywords([]string{strings.Join(myKeyword.SearchKeywords, " ")})
 	we.userSearchTrack.addValidSearch()
@@ -182,6 +258,55 @@ func (we *Web) searchHandler(ws *websocket.Conn) {
 	we.send2Kad(ws, myKeywordStruct)
 }
 
+func (we *Web) chooseItems(ws *websocket.Conn, items []*com.Item) ([]*com.Item, string) {
+	data, _ := json.Marshal(&webCandidates{Candidates: items})
+	if _, err := ws.Write(data); err != nil {
+		return nil, "发送数据错误，请重试！"
+	}
+
+	// read choice, it's one JSON message of any size
+	ws.SetReadDeadline(time.Now().Add(userChooseWaitingTime * time.Second))
+	defer ws.SetReadDeadline(time.Time{})
+
+	var msg []byte
+	if err := websocket.Message.Receive(ws, &msg); err != nil {
+		return nil, "选择超时，请重试！"
+	}
+
+	var choice webChoice
+	if err := json.Unmarshal(msg, &choice); err != nil {
+		return nil, "选择错误，请重试！"
+	}
+
+	chosenItems, ok := getChosenItems(items, choice.Choose)
+	if !ok {
+		return nil, "选择错误，请重试！"
+	}
+
+	return chosenItems, ""
+}
+
+// getChosenItems is items of indexes @choose, all items if it's empty.
+// It's false if any index is out of range or duplicated.
+func getChosenItems(items []*com.Item, choose []int) ([]*com.Item, bool) {
+	if len(choose) == 0 {
+		return items, true
+	}
+
+	var chosenItems []*com.Item
+	chosenMap := make(map[int]bool)
+	for _, i := range choose {
+		if i < 0 || i >= len(items) || chosenMap[i] {
+			return nil, false
+		}
+
+		chosenMap[i] = true
+		chosenItems = append(chosenItems, items[i])
+	}
+
+	return chosenItems, true
+}
+
 func (we *Web) homeHandler(w http.ResponseWriter, r *http.Request) {
 	homeData := &HomeData{Host: "ws://" + r.Host + "/search",
 		SearchStats: we.userSearchTrack.getSearchStats(),
@@ -201,15 +326,37 @@ func (we *Web) statsHandler(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte(s))
 }
 
+// metricsHandler exposes KAD metrics in Prometheus text format, only for local like admin pages.
+func (we *Web) metricsHandler(w http.ResponseWriter, r *http.Request) {
+	if !isLocalRequest(r) || we.kadMetrics == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	we.kadMetrics.WritePrometheus(w)
+}
+
 func (we *Web) startServer() {
 	com.HhjLog.Info("Web Server is running...")
 
 	http.HandleFunc("/", we.homeHandler)
 	http.HandleFunc("/1979", we.statsHandler)
+	http.HandleFunc("/collection", we.collectionHandler)
+	http.HandleFunc("/metrics", we.metricsHandler)
+	http.HandleFunc("/admin", we.adminHandler)
+	http.HandleFunc("/admin.json", we.adminJSONHandler)
+	http.HandleFunc("/admin/filter/reload", we.filterReloadHandler)
 	http.Handle("/search", websocket.Handler(we.searchHandler))
 
+	// flags are before arguments, e.g. hahajing -filter cjk server
+	args := os.Args[1:]
+	if flag.Parsed() {
+		args = flag.Args()
+	}
+
 	var err error
-	if len(os.Args) > 1 && os.Args[1] == "server" {
+	if len(args) > 0 && args[0] == "server" {
 		err = http.ListenAndServe(":80", nil)
 	} else {
 		err = http.ListenAndServe(":66", nil)
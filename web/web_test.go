@@ -0,0 +1,87 @@
+package web
+
+import (
+	"encoding/json"
+	"hahajing/com"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/websocket"
+)
+
+// chooseResult is what chooseItems returns, written back for test.
+type chooseResult struct {
+	OrgNames []string
+	ErrorStr string
+}
+
+func TestChooseItems(t *testing.T) {
+	items := []*com.Item{{OrgName: "It", Year: 1990}, {OrgName: "It", Year: 2017}, {OrgName: "It Follows", Year: 2014}}
+
+	var we Web
+	server := httptest.NewServer(websocket.Handler(func(ws *websocket.Conn) {
+		chosenItems, errStr := we.chooseItems(ws, items)
+
+		res := chooseResult{ErrorStr: errStr}
+		for _, item := range chosenItems {
+			res.OrgNames = append(res.OrgNames, item.OrgName)
+		}
+		websocket.JSON.Send(ws, &res)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		choice   string
+		orgNames []string // nil if it's error
+	}{
+		{`{}`, []string{"It", "It", "It Follows"}},
+		{`{"Choose":[]}`, []string{"It", "It", "It Follows"}},
+		{`{"Choose":[2,0]}`, []string{"It Follows", "It"}},
+
+		// longer than one read
+		{`{"Choose":[1],"Padding":"` + strings.Repeat("x", 2048) + `"}`, []string{"It"}},
+
+		// out of range, duplicate or bad
+		{`{"Choose":[3]}`, nil},
+		{`{"Choose":[-1]}`, nil},
+		{`{"Choose":[1,1]}`, nil},
+		{`{"Choose":"1"}`, nil},
+		{`1]`, nil},
+	}
+
+	for _, test := range tests {
+		ws, err := websocket.Dial("ws"+strings.TrimPrefix(server.URL, "http"), "", "http://localhost/")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var candidates webCandidates
+		if err := websocket.JSON.Receive(ws, &candidates); err != nil || len(candidates.Candidates) != len(items) {
+			t.Fatalf("candidates: %v, %v", candidates, err)
+		}
+
+		if err := websocket.Message.Send(ws, test.choice); err != nil {
+			t.Fatal(err)
+		}
+
+		var res chooseResult
+		if err := websocket.JSON.Receive(ws, &res); err != nil {
+			t.Fatal(err)
+		}
+		ws.Close()
+
+		if test.orgNames == nil {
+			if res.ErrorStr == "" {
+				data, _ := json.Marshal(&res)
+				t.Errorf("choice %.30s: %s, expected error", test.choice, data)
+			}
+			continue
+		}
+
+		if res.ErrorStr != "" || !reflect.DeepEqual(res.OrgNames, test.orgNames) {
+			t.Errorf("choice %.30s: %v %s, expected %v", test.choice, res.OrgNames, res.ErrorStr, test.orgNames)
+		}
+	}
+}